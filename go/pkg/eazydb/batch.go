@@ -0,0 +1,305 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// rows added per statement unless BatchSize is set
+const defaultBatchSize = 1000
+
+// Batch describes one statement of a bulk insert. Start and End are the positions
+// of its rows in the slice passed to Add, so failed batches can be retried with rows[Start:End]
+type Batch struct {
+	Index        int
+	Start        int
+	End          int
+	Rows         int
+	RowsAffected int
+	Duration     time.Duration
+	Err          error
+}
+
+type chunk struct {
+	index int
+	start int
+	end   int
+	rows  interface{}
+}
+
+// BatchSize sets how many rows are sent per statement when adding a slice.
+// Batches are not run in a transaction, so earlier batches stay written if a later one fails.
+func (q *Query) BatchSize(size int) *Query {
+	if size <= 0 {
+		q.err = fmt.Errorf("batch size must be greater than 0, got %v", size)
+		return q
+	}
+	q.batchSize = size
+	return q
+}
+
+// Copy streams the rows with COPY FROM STDIN instead of INSERT statements.
+// All batches are written in a single transaction. Postgres only.
+func (q *Query) Copy() *Query {
+	if q.op != dbtypes.INSERT {
+		q.err = fmt.Errorf("Copy can only be used with Add, table operation is %v", q.op)
+		return q
+	}
+	q.copy = true
+	return q
+}
+
+// constructQueries returns the statements to run, a statement per batch when adding rows
+func (q *Query) constructQueries() ([]statement, error) {
+	if q.op != dbtypes.INSERT {
+		stmt, err := q.constructQuery()
+		if err != nil {
+			return nil, err
+		}
+		return []statement{stmt}, nil
+	}
+
+	chunks, err := q.chunks()
+	if err != nil {
+		return nil, err
+	}
+
+	if q.copy {
+		if q.conflict != nil || len(q.returning) > 0 {
+			return nil, fmt.Errorf("Copy cannot be combined with OnConflict or Returning")
+		}
+		names, err := q.copyColumns(chunks[0].rows)
+		if err != nil {
+			return nil, err
+		}
+		// only used to describe the query, the rows are streamed by handleCopy
+		return []statement{{sql: pq.CopyIn(q.name, names...)}}, nil
+	}
+
+	queries := make([]statement, len(chunks))
+	for i, chunk := range chunks {
+		// each statement numbers its own placeholders
+		b := &binder{}
+		stmt, err := q.constructInsertQuery(b, fmt.Sprintf("%v %v", q.op, q.name), chunk.rows)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = statement{sql: stmt, args: b.args}
+	}
+	return queries, nil
+}
+
+// joinStatements returns the SQL of the statements a line each, along with all of their args
+func joinStatements(queries []statement) (string, []interface{}) {
+	sqls := make([]string, len(queries))
+	var args []interface{}
+	for i, stmt := range queries {
+		sqls[i] = stmt.sql
+		args = append(args, stmt.values()...)
+	}
+	return strings.Join(sqls, "\n"), args
+}
+
+// chunks splits q.fields into slices of at most the batch size, smaller when
+// Parallel has more workers than that would make batches
+func (q *Query) chunks() ([]chunk, error) {
+	if q.fields == nil {
+		return nil, fmt.Errorf("fields cannot be nil")
+	}
+
+	v := reflect.ValueOf(q.fields)
+	if v.Kind() != reflect.Slice {
+		return []chunk{{start: 0, end: 1, rows: q.fields}}, nil
+	}
+	if v.Len() == 0 {
+		return nil, fmt.Errorf("no rows were passed to Add")
+	}
+
+	size := q.batchSize
+	if size == 0 {
+		size = defaultBatchSize
+	}
+	// give every worker a batch, even when the rows would fit in fewer
+	if q.workers > 1 {
+		size = min(size, (v.Len()+q.workers-1)/q.workers)
+	}
+
+	var chunks []chunk
+	for start := 0; start < v.Len(); start += size {
+		end := start + size
+		if end > v.Len() {
+			end = v.Len()
+		}
+		chunks = append(chunks, chunk{
+			index: len(chunks),
+			start: start,
+			end:   end,
+			rows:  v.Slice(start, end).Interface(),
+		})
+	}
+	return chunks, nil
+}
+
+func (q *Query) handleInsert(queries []statement, target interface{}) (*Metadata, error) {
+	chunks, err := q.chunks()
+	if err != nil {
+		return nil, err
+	}
+
+	if q.workers > 1 && len(chunks) > 1 {
+		return q.handleParallel(queries, chunks, target)
+	}
+
+	if q.copy {
+		return q.handleCopy(chunks)
+	}
+
+	var metadata *Metadata = &Metadata{}
+	metadata.Query, metadata.Args = joinStatements(queries)
+	var returned []map[string]interface{}
+	for i, query := range queries {
+		var batch *Metadata
+		if q.hasReturning() {
+			var data []map[string]interface{}
+			batch, data, err = q.queryReturning(query)
+			returned = append(returned, data...)
+		} else {
+			batch, err = q.handleExec(query)
+		}
+		if err != nil {
+			return metadata, fmt.Errorf("batch %v of %v failed: %w", i+1, len(queries), err)
+		}
+		q.log.Debug("batch added rows", "table", q.name, "batch", i+1, "batches", len(queries), "rows", batch.RowsAffected)
+		metadata.addBatch(chunks[i], batch)
+	}
+
+	return metadata, q.readReturned(returned, target)
+}
+
+// handleCopy streams each batch to postgres with COPY, committing once every batch is written.
+// If the query is already bound to a transaction the caller is left to commit it.
+func (q *Query) handleCopy(chunks []chunk) (*Metadata, error) {
+	var metadata *Metadata = &Metadata{}
+
+	txn := q.tx
+	if txn == nil {
+		var err error
+		txn, err = q.db.BeginTx(q.ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer txn.Rollback()
+	}
+
+	for i, chunk := range chunks {
+		names, rows, err := q.copyRows(chunk.rows)
+		if err != nil {
+			return metadata, err
+		}
+		metadata.Query = pq.CopyIn(q.name, names...)
+
+		now := time.Now()
+		err = copyBatch(q.ctx, txn, metadata.Query, rows)
+		duration := time.Since(now)
+		if err != nil {
+			q.logQuery(statement{sql: metadata.Query}, duration, 0, err)
+			return metadata, fmt.Errorf("batch %v of %v failed: %w", i+1, len(chunks), err)
+		}
+		q.logQuery(statement{sql: metadata.Query}, duration, len(rows), nil)
+
+		batch := &Metadata{Duration: duration, RowsAffected: len(rows), RowsInserted: len(rows)}
+		q.log.Debug("batch copied rows", "table", q.name, "batch", i+1, "batches", len(chunks), "rows", len(rows))
+		metadata.addBatch(chunk, batch)
+	}
+
+	if q.tx != nil {
+		return metadata, nil
+	}
+	if err := txn.Commit(); err != nil {
+		return metadata, err
+	}
+	return metadata, nil
+}
+
+// copyBatch streams the rows of one batch through a COPY statement
+func copyBatch(ctx context.Context, txn *sql.Tx, query string, rows [][]interface{}) error {
+	stmt, err := txn.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+	// flushes the buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+// copyColumns returns the columns set by any row of the chunk, the same columns an INSERT would use
+func (q *Query) copyColumns(chunk interface{}) ([]string, error) {
+	v := reflect.ValueOf(chunk)
+	if v.Kind() != reflect.Slice {
+		v = reflect.ValueOf([]interface{}{chunk})
+	}
+	return q.insertColumns(v)
+}
+
+// copyRows returns the columns of the chunk and the values of every row in that order.
+// COPY has no DEFAULT, so a row that left out a column another row set sends its zero value
+func (q *Query) copyRows(chunk interface{}) ([]string, [][]interface{}, error) {
+	v := reflect.ValueOf(chunk)
+	if v.Kind() != reflect.Slice {
+		v = reflect.ValueOf([]interface{}{chunk})
+	}
+
+	names, err := q.insertColumns(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([][]interface{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		all, err := constructFields(v.Index(i).Interface(), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		vals := make(map[string]interface{}, len(all))
+		for _, f := range all {
+			vals[f.Name] = f.Val
+		}
+		rows[i] = make([]interface{}, len(names))
+		for j, name := range names {
+			rows[i][j] = vals[name]
+		}
+	}
+	return names, rows, nil
+}
+
+func (m *Metadata) addBatch(c chunk, batch *Metadata) {
+	m.Duration += batch.Duration
+	m.RowsAffected += batch.RowsAffected
+	m.RowsReturned += batch.RowsReturned
+	m.RowsInserted += batch.RowsInserted
+	m.RowsUpdated += batch.RowsUpdated
+	m.Batches = append(m.Batches, Batch{
+		Index:        c.index,
+		Start:        c.start,
+		End:          c.end,
+		Rows:         c.end - c.start,
+		RowsAffected: batch.RowsAffected,
+		Duration:     batch.Duration,
+	})
+}
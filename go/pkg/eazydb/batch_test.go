@@ -0,0 +1,95 @@
+package eazydb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type batchUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Age   int    `json:"age"`
+}
+
+func TestBatchSizeSplitsStatements(t *testing.T) {
+	c, fake := newTestClient(t)
+	users := []batchUser{{"a", "a@x.io", 1}, {"b", "b@x.io", 2}, {"c", "c@x.io", 3}}
+
+	metadata, err := c.Table("users").Add(users).BatchSize(2).Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"INSERT INTO users (name, email, age) VALUES ($1, $2, $3), ($4, $5, $6); [a a@x.io 1 b b@x.io 2]",
+		"INSERT INTO users (name, email, age) VALUES ($1, $2, $3); [c c@x.io 3]",
+	}
+	if got := fake.queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	if metadata.RowsAffected != 3 || len(metadata.Batches) != 2 {
+		t.Fatalf("got %v rows affected in %v batches, want 3 in 2", metadata.RowsAffected, len(metadata.Batches))
+	}
+	if b := metadata.Batches[1]; b.Index != 1 || b.Start != 2 || b.End != 3 || b.Rows != 1 {
+		t.Errorf("got second batch %+v", b)
+	}
+}
+
+func TestInsertUsesColumnsOfEveryRow(t *testing.T) {
+	c, _ := newTestClient(t)
+	users := []batchUser{{Name: "a"}, {Name: "b", Age: 30}, {Email: "c@x.io"}}
+
+	metadata, err := c.Table("users").Add(users).Dry().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT INTO users (name, email, age) VALUES ($1, DEFAULT, DEFAULT), ($2, DEFAULT, $3), (DEFAULT, $4, DEFAULT);"
+	if metadata.Query != want {
+		t.Errorf("got  %s\nwant %s", metadata.Query, want)
+	}
+	if args := []interface{}{"a", "b", 30, "c@x.io"}; !reflect.DeepEqual(metadata.Args, args) {
+		t.Errorf("got args %v, want %v", metadata.Args, args)
+	}
+}
+
+func TestCopyStreamsRows(t *testing.T) {
+	c, fake := newTestClient(t)
+	users := []batchUser{{Name: "a"}, {Name: "b", Age: 30}}
+
+	metadata, err := c.Table("users").Add(users).Copy().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyIn := `COPY "users" ("name", "age") FROM STDIN`
+	want := []string{"BEGIN", copyIn + " [a 0]", copyIn + " [b 30]", copyIn, "COMMIT"}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	if metadata.RowsInserted != 2 || metadata.Query != copyIn {
+		t.Errorf("got %v rows inserted by %q", metadata.RowsInserted, metadata.Query)
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+	users := []batchUser{{Name: "a"}}
+
+	tests := []struct {
+		name  string
+		query *Query
+		err   string
+	}{
+		{"zero batch size", c.Table("users").Add(users).BatchSize(0), "batch size must be greater than 0"},
+		{"copy on get", c.Table("users").Get(batchUser{}).Copy(), "Copy can only be used with Add"},
+		{"copy with upsert", c.Table("users").Add(users).Copy().OnConflict("email").DoNothing(), "Copy cannot be combined"},
+		{"no rows", c.Table("users").Add([]batchUser{}), "no rows were passed to Add"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.query.Dry().Exec()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ConnectRetry makes NewClient wait for the database to accept connections instead of
+// failing on the first ping, eg: while a container is starting
+//
+//	eazydb.ClientOptions{ConnectRetry: eazydb.ConnectRetry{MaxAttempts: 10, Deadline: time.Minute}}
+type ConnectRetry struct {
+	// MaxAttempts counts the first attempt. 0 pings once, or keeps trying until Deadline when it is set
+	MaxAttempts int
+	// BaseDelay doubles after each failed attempt up to MaxDelay. Defaults to 50ms and 2s
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Deadline bounds the total time spent connecting, 0 means no limit. Either MaxAttempts
+	// or Deadline turns retries on
+	Deadline time.Duration
+}
+
+// connectRetryable retries anything but bad credentials and a missing database,
+// as a starting database can refuse connections, reset them or not resolve yet
+func connectRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Class() != "28" && pqErr.Code != "3D000"
+	}
+	return true
+}
+
+// missingDatabase reports whether the database named in the connection doesn't exist
+func missingDatabase(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "3D000"
+}
+
+// waitForDatabase pings until the database is reachable, creating it first if it is missing
+// and CreateDatabase is set
+func waitForDatabase(db *sql.DB, opt *ClientOptions, log Logger) error {
+	ctx := context.Background()
+	if opt.ConnectRetry.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.ConnectRetry.Deadline)
+		defer cancel()
+	}
+
+	policy := RetryPolicy{
+		MaxAttempts: opt.ConnectRetry.MaxAttempts,
+		BaseDelay:   opt.ConnectRetry.BaseDelay,
+		MaxDelay:    opt.ConnectRetry.MaxDelay,
+		Retryable:   connectRetryable,
+	}
+	if policy.MaxAttempts == 0 && opt.ConnectRetry.Deadline > 0 {
+		policy.MaxAttempts = math.MaxInt
+	}
+	created := false
+	var last error
+	attempts, err := retry(ctx, policy, log, "connecting to the database", func(int) error {
+		err := db.PingContext(ctx)
+		if err != nil && ctx.Err() != nil && last != nil {
+			// the deadline passed during the ping, the error before it says why it failed
+			return last
+		}
+		last = err
+		if !opt.CreateDatabase || created || !missingDatabase(err) {
+			return err
+		}
+		if err := createDatabase(ctx, opt, log); err != nil {
+			return err
+		}
+		created = true
+		return db.PingContext(ctx)
+	})
+	if err != nil {
+		if attempts > 1 {
+			return fmt.Errorf("could not connect to the database after %v attempts: %w", attempts, err)
+		}
+		return err
+	}
+	if attempts > 1 {
+		log.Info("connected to the database", "attempts", attempts)
+	}
+	return nil
+}
+
+// createDatabase connects to the postgres maintenance database to create the target database
+func createDatabase(ctx context.Context, opt *ClientOptions, log Logger) error {
+	name, err := opt.databaseName()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("could not create database, no database name is set")
+	}
+
+	admin, err := opt.withDatabase("postgres")
+	if err != nil {
+		return err
+	}
+	db, err := openDB(admin)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	log.Info("database does not exist, creating it", "database", name)
+	_, err = db.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s;", pq.QuoteIdentifier(name)))
+	var pqErr *pq.Error
+	// another process created it first
+	if errors.As(err, &pqErr) && pqErr.Code == "42P04" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not create database %s: %w", name, err)
+	}
+	return nil
+}
+
+// databaseName returns the database the options connect to
+func (opt *ClientOptions) databaseName() (string, error) {
+	if opt.DSN == "" {
+		return opt.Name, nil
+	}
+	if strings.Contains(opt.DSN, "://") {
+		u, err := url.Parse(opt.DSN)
+		if err != nil {
+			return "", fmt.Errorf("could not parse DSN: %v", err)
+		}
+		return strings.TrimPrefix(u.Path, "/"), nil
+	}
+	params, err := parseParams(opt.DSN)
+	if err != nil {
+		return "", err
+	}
+	return params["dbname"], nil
+}
+
+// withDatabase returns a copy of the options connecting to another database
+func (opt *ClientOptions) withDatabase(name string) (*ClientOptions, error) {
+	other := *opt
+	switch {
+	case opt.DSN == "":
+		other.Name = name
+	case strings.Contains(opt.DSN, "://"):
+		u, err := url.Parse(opt.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse DSN: %v", err)
+		}
+		u.Path = "/" + name
+		other.DSN = u.String()
+	default:
+		// later values win
+		other.DSN = fmt.Sprintf("%s dbname=%s", opt.DSN, quoteParam(name))
+	}
+	return &other, nil
+}
@@ -0,0 +1,91 @@
+package eazydb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestDatabaseName(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"host=db dbname=app", "app"},
+		{"dbname='my db' host=db", "my db"},
+		{`dbname = 'it\'s' user=app`, "it's"},
+		{`dbname=a\ b`, "a b"},
+		{"dbname=old dbname=new", "new"},
+		{"host=db user='dbname=fake'", ""},
+		{"postgres://app@db:5432/app?sslmode=disable", "app"},
+	}
+	for _, tt := range tests {
+		opt := ClientOptions{DSN: tt.dsn}
+		got, err := opt.databaseName()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dsn, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+
+	for _, dsn := range []string{"dbname='app", "host", "=app"} {
+		opt := ClientOptions{DSN: dsn}
+		if _, err := opt.databaseName(); err == nil {
+			t.Errorf("%s: want an error", dsn)
+		}
+	}
+}
+
+func TestWithDatabase(t *testing.T) {
+	opt := ClientOptions{DSN: "host=db dbname='my db'"}
+	other, err := opt.withDatabase("postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := other.databaseName(); name != "postgres" {
+		t.Errorf("got %q", name)
+	}
+	if name, _ := opt.databaseName(); name != "my db" {
+		t.Errorf("the original options changed to %q", name)
+	}
+}
+
+func TestWaitForDatabase(t *testing.T) {
+	unavailable := &pq.Error{Code: "57P03"}
+	tests := []struct {
+		name    string
+		retry   ConnectRetry
+		err     error
+		retried bool
+	}{
+		{"pings once by default", ConnectRetry{}, unavailable, false},
+		{"stops after max attempts", ConnectRetry{MaxAttempts: 3, BaseDelay: time.Millisecond}, unavailable, true},
+		{"deadline alone retries", ConnectRetry{Deadline: 50 * time.Millisecond, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}, unavailable, true},
+		{"bad credentials are not retried", ConnectRetry{MaxAttempts: 3, BaseDelay: time.Millisecond}, &pq.Error{Code: "28P01"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t)
+			fake.pingErr = tt.err
+			opt := &ClientOptions{ConnectRetry: tt.retry}
+
+			err := waitForDatabase(db, opt, initLogger(nil, false))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want it to wrap %v", err, tt.err)
+			}
+			if retried := strings.Contains(err.Error(), "could not connect to the database after"); retried != tt.retried {
+				t.Errorf("got error %v, want retried %v", err, tt.retried)
+			}
+		})
+	}
+
+	db, _ := newFakeDB(t)
+	if err := waitForDatabase(db, &ClientOptions{ConnectRetry: ConnectRetry{Deadline: time.Second}}, initLogger(nil, false)); err != nil {
+		t.Errorf("got error %v from a reachable database", err)
+	}
+}
@@ -13,3 +13,14 @@ const (
 	SERIAL VARIABLE_TYPE = "SERIAL"
 	IGNORE VARIABLE_TYPE = "IGNORE"
 )
+
+type FK_ACTION string
+
+// what happens to referencing rows when the referenced row is deleted or updated
+const (
+	NO_ACTION   FK_ACTION = "NO ACTION"
+	RESTRICT    FK_ACTION = "RESTRICT"
+	CASCADE     FK_ACTION = "CASCADE"
+	SET_NULL    FK_ACTION = "SET NULL"
+	SET_DEFAULT FK_ACTION = "SET DEFAULT"
+)
@@ -0,0 +1,285 @@
+package eazydb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagOptions are the options set in a `db` struct tag, eg: `db:",notnull,unique,default=0"`
+type tagOptions struct {
+	notNull     bool
+	unique      bool
+	primaryKey  bool
+	defaultVal  string
+	indexed     bool
+	uniqueIndex bool
+	index       string
+	references  string
+	onDelete    FK_ACTION
+	onUpdate    FK_ACTION
+	hasOne      string
+	hasMany     string
+	manyToMany  string
+	through     string
+	key         string
+	refKey      string
+	createdAt   bool
+	updatedAt   bool
+	sensitive   bool
+}
+
+func parseTag(f reflect.StructField) tagOptions {
+	var opts tagOptions
+	parts := splitTag(f.Tag.Get("db"))
+	// the first part is reserved for the column name
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "notnull":
+			opts.notNull = true
+		case part == "unique":
+			opts.unique = true
+		case part == "pk":
+			opts.primaryKey = true
+		case part == "createdat":
+			opts.createdAt = true
+		case part == "updatedat":
+			opts.updatedAt = true
+		case part == "sensitive":
+			opts.sensitive = true
+		case strings.HasPrefix(part, "default="):
+			opts.defaultVal = strings.TrimPrefix(part, "default=")
+		case part == "index", part == "uniqueindex":
+			opts.indexed = true
+			opts.uniqueIndex = part == "uniqueindex"
+		case strings.HasPrefix(part, "index="), strings.HasPrefix(part, "uniqueindex="):
+			opts.indexed = true
+			opts.uniqueIndex = strings.HasPrefix(part, "uniqueindex=")
+			opts.index = part[strings.Index(part, "=")+1:]
+		case strings.HasPrefix(part, "references="):
+			opts.references = strings.TrimPrefix(part, "references=")
+		case strings.HasPrefix(part, "ondelete="):
+			opts.onDelete = tagAction(strings.TrimPrefix(part, "ondelete="))
+		case strings.HasPrefix(part, "onupdate="):
+			opts.onUpdate = tagAction(strings.TrimPrefix(part, "onupdate="))
+		case strings.HasPrefix(part, "hasone="):
+			opts.hasOne = strings.TrimPrefix(part, "hasone=")
+		case strings.HasPrefix(part, "hasmany="):
+			opts.hasMany = strings.TrimPrefix(part, "hasmany=")
+		case strings.HasPrefix(part, "manytomany="):
+			opts.manyToMany = strings.TrimPrefix(part, "manytomany=")
+		case strings.HasPrefix(part, "through="):
+			opts.through = strings.TrimPrefix(part, "through=")
+		case strings.HasPrefix(part, "key="):
+			opts.key = strings.TrimPrefix(part, "key=")
+		case strings.HasPrefix(part, "refkey="):
+			opts.refKey = strings.TrimPrefix(part, "refkey=")
+		}
+	}
+	return opts
+}
+
+// splitTag splits on commas that are not inside brackets, so through=user_tags(user_id,tag_id) stays whole
+func splitTag(tag string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range tag {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, tag[start:])
+}
+
+// tagAction turns set_null into SET NULL
+func tagAction(action string) FK_ACTION {
+	return FK_ACTION(strings.ToUpper(strings.ReplaceAll(action, "_", " ")))
+}
+
+// PrimaryKey sets a primary key made of one or more columns
+//
+//	NewTable("orders").Fields(Order{}).PrimaryKey("tenant_id", "id")
+func (t *TableInstance) PrimaryKey(columns ...string) *TableInstance {
+	if len(columns) == 0 {
+		t.err = fmt.Errorf("PrimaryKey requires at least one column")
+		return t
+	}
+	t.primaryKey = columns
+	return t
+}
+
+// Unique adds a unique constraint across the given columns, can be called more than once
+func (t *TableInstance) Unique(columns ...string) *TableInstance {
+	if len(columns) == 0 {
+		t.err = fmt.Errorf("Unique requires at least one column")
+		return t
+	}
+	t.uniques = append(t.uniques, columns)
+	return t
+}
+
+// Check adds a check constraint, the expression is raw SQL
+//
+//	NewTable("users").Fields(User{}).Check("age >= 0")
+func (t *TableInstance) Check(expr string) *TableInstance {
+	t.checks = append(t.checks, expr)
+	return t
+}
+
+// NotNull stops the given columns from being set to NULL
+func (t *TableInstance) NotNull(columns ...string) *TableInstance {
+	if t.notNull == nil {
+		t.notNull = make(map[string]bool)
+	}
+	for _, col := range columns {
+		t.notNull[col] = true
+	}
+	return t
+}
+
+// Default sets the value used for a column when a row is added without it.
+// Use an Expression such as eazydb.Now() for values the database works out
+func (t *TableInstance) Default(column string, val interface{}) *TableInstance {
+	if t.defaults == nil {
+		t.defaults = make(map[string]string)
+	}
+	t.defaults[column] = prepareValInsert(val)
+	return t
+}
+
+// keyColumns returns the primary key of the table in order
+func (t *TableInstance) keyColumns(fields []field) []string {
+	if len(t.primaryKey) > 0 {
+		return t.primaryKey
+	}
+	var keys []string
+	for _, f := range fields {
+		if f.primaryKey {
+			keys = append(keys, f.Name)
+		}
+	}
+	if len(keys) > 0 {
+		return keys
+	}
+	if t.key != nil {
+		return []string{t.key.Name}
+	}
+	return nil
+}
+
+// Produces constraints like below
+//
+//	PRIMARY KEY (tenant_id, id), UNIQUE (email), CHECK (age >= 0)
+func (t *TableInstance) constructConstraints(fields []field) ([]string, error) {
+	var constraints []string
+
+	keys := t.keyColumns(fields)
+	// a single Key is declared inline with its column
+	if len(keys) > 0 && !t.inlineKey(keys) {
+		constraints = append(constraints, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keys, ", ")))
+	}
+	for _, unique := range t.uniques {
+		constraints = append(constraints, fmt.Sprintf("UNIQUE (%s)", strings.Join(unique, ", ")))
+	}
+	for _, check := range t.checks {
+		constraints = append(constraints, fmt.Sprintf("CHECK (%s)", check))
+	}
+
+	fks, err := t.constructForeignKeys(fields)
+	if err != nil {
+		return nil, err
+	}
+	for _, fk := range fks {
+		constraints = append(constraints, fk.constructQuery())
+	}
+	return constraints, nil
+}
+
+// validateColumns checks every column named in a constraint is a field of the table
+func (t *TableInstance) validateColumns(fields []field) error {
+	known := func(col string) bool {
+		return hasField(fields, col) || (t.key != nil && t.key.Name == col)
+	}
+
+	columns := append([]string{}, t.primaryKey...)
+	for _, unique := range t.uniques {
+		columns = append(columns, unique...)
+	}
+	for col := range t.notNull {
+		columns = append(columns, col)
+	}
+	for col := range t.defaults {
+		columns = append(columns, col)
+	}
+	for _, fk := range t.foreignKeys {
+		columns = append(columns, fk.Columns...)
+	}
+	for _, col := range columns {
+		if !known(col) {
+			return fmt.Errorf("column %v is used in a constraint but is not a field of the table", col)
+		}
+	}
+	return nil
+}
+
+func (t *TableInstance) inlineKey(keys []string) bool {
+	return t.key != nil && len(keys) == 1 && keys[0] == t.key.Name
+}
+
+// constructIndexes returns an index per `db:",index"` tag. Fields tagged with the same
+// index name share a single index, in the order they appear in the struct
+//
+//	CREATE INDEX IF NOT EXISTS users_email_idx ON users (email);
+func (t *TableInstance) constructIndexes(fields []field) []string {
+	var names []string
+	indexes := make(map[string]*IndexInstance)
+	for _, f := range fields {
+		if !f.indexed {
+			continue
+		}
+		name := f.index
+		if name == "" {
+			name = fmt.Sprintf("%s_%s_idx", t.name, f.Name)
+		}
+		if _, ok := indexes[name]; !ok {
+			indexes[name] = &IndexInstance{name: name, table: t.name}
+			names = append(names, name)
+		}
+		indexes[name].columns = append(indexes[name].columns, f.Name)
+		indexes[name].unique = indexes[name].unique || f.uniqueIndex
+	}
+
+	stmts := make([]string, 0, len(names))
+	for _, name := range names {
+		// cannot fail, the table and columns are always set
+		stmt, _ := indexes[name].constructQuery()
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// Produces a column like below
+//
+//	age INT NOT NULL DEFAULT 18
+func (f *field) definition() string {
+	stmt := fmt.Sprintf("%s %v", f.Name, f.SQLType)
+	if f.notNull {
+		stmt += " NOT NULL"
+	}
+	if f.unique {
+		stmt += " UNIQUE"
+	}
+	if f.defaultVal != "" {
+		stmt += " DEFAULT " + f.defaultVal
+	}
+	return stmt
+}
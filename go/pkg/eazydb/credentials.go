@@ -0,0 +1,157 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// CredentialProvider returns the user and password to connect with. It is called each time
+// the pool opens a new connection, so rotated passwords are used without a restart.
+// An empty user keeps ClientOptions.User, or the user in DSN if that is empty too
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (user string, password string, err error)
+}
+
+// CredentialFunc lets a function be used as a CredentialProvider
+type CredentialFunc func(ctx context.Context) (string, string, error)
+
+func (f CredentialFunc) Credentials(ctx context.Context) (string, string, error) {
+	return f(ctx)
+}
+
+type staticCredentials struct {
+	user     string
+	password string
+}
+
+func (s staticCredentials) Credentials(context.Context) (string, string, error) {
+	return s.user, s.password, nil
+}
+
+// StaticCredentials always connects with the same user and password
+func StaticCredentials(user string, password string) CredentialProvider {
+	return staticCredentials{user: user, password: password}
+}
+
+type envCredentials struct {
+	userEnv     string
+	passwordEnv string
+}
+
+func (e envCredentials) Credentials(context.Context) (string, string, error) {
+	user, err := readEnv(e.userEnv)
+	if err != nil {
+		return "", "", err
+	}
+	password, err := readEnv(e.passwordEnv)
+	if err != nil {
+		return "", "", err
+	}
+	return user, password, nil
+}
+
+// EnvCredentials reads the user and password from environment variables each time a connection
+// is opened. If a variable is empty and the same name ending in _FILE is set, eg: DB_PASS_FILE,
+// the value is read from that file instead
+func EnvCredentials(userEnv string, passwordEnv string) CredentialProvider {
+	return envCredentials{userEnv: userEnv, passwordEnv: passwordEnv}
+}
+
+type fileCredentials struct {
+	user         string
+	passwordPath string
+}
+
+func (f fileCredentials) Credentials(context.Context) (string, string, error) {
+	password, err := readSecretFile(f.passwordPath)
+	return f.user, password, err
+}
+
+// FileCredentials reads the password from a file each time a connection is opened,
+// such as a mounted secret that is rotated in place
+func FileCredentials(user string, passwordPath string) CredentialProvider {
+	return fileCredentials{user: user, passwordPath: passwordPath}
+}
+
+func readEnv(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	if val := os.Getenv(name); val != "" {
+		return val, nil
+	}
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		return readSecretFile(path)
+	}
+	return "", nil
+}
+
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read credentials: %v", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// credentialConnector opens each connection with freshly fetched credentials
+type credentialConnector struct {
+	opt      ClientOptions
+	provider CredentialProvider
+}
+
+func (c *credentialConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	user, password, err := c.provider.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user == "" {
+		user = c.opt.User
+	}
+
+	dsn, err := c.opt.dataSourceName()
+	if err != nil {
+		return nil, err
+	}
+	dsn, err = withCredentials(dsn, user, password)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *credentialConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// withCredentials sets the user and password of a connection string. An empty user keeps
+// the one already in the connection string
+func withCredentials(dsn string, user string, password string) (string, error) {
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("could not parse DSN: %v", err)
+		}
+		if user == "" && u.User != nil {
+			user = u.User.Username()
+		}
+		u.User = url.UserPassword(user, password)
+		return u.String(), nil
+	}
+	// later values win
+	if user != "" {
+		dsn = fmt.Sprintf("%s user=%s", dsn, quoteParam(user))
+	}
+	return fmt.Sprintf("%s password=%s", dsn, quoteParam(password)), nil
+}
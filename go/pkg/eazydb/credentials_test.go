@@ -0,0 +1,80 @@
+package eazydb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		dsn      string
+		user     string
+		password string
+		want     string
+	}{
+		{"url", "postgres://old:pw@db/app", "app", "new", "postgres://app:new@db/app"},
+		{"url keeps its user", "postgres://app@db/app", "", "new", "postgres://app:new@db/app"},
+		{"url escapes the password", "postgres://db/app", "app", "p@ss word", "postgres://app:p%40ss%20word@db/app"},
+		{"key value", "host=db dbname=app", "app", "p w", "host=db dbname=app user=app password='p w'"},
+		{"key value keeps its user", "host=db user=app dbname=app", "", "new", "host=db user=app dbname=app password=new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := withCredentials(tt.dsn, tt.user, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialProviders(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "db-pass")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_PASS_FILE", path)
+	tests := []struct {
+		name     string
+		provider CredentialProvider
+		user     string
+		password string
+	}{
+		{"static", StaticCredentials("app", "pw"), "app", "pw"},
+		{"env falls back to _FILE", EnvCredentials("DB_USER", "DB_PASS"), "app", "from-file"},
+		{"file", FileCredentials("app", path), "app", "from-file"},
+		{"func", CredentialFunc(func(context.Context) (string, string, error) { return "", "token", nil }), "", "token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, password, err := tt.provider.Credentials(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user != tt.user || password != tt.password {
+				t.Errorf("got %q %q, want %q %q", user, password, tt.user, tt.password)
+			}
+		})
+	}
+
+	// the file is read again on each call so a rotated secret is picked up
+	if err := os.WriteFile(path, []byte("rotated"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, password, _ := FileCredentials("app", path).Credentials(context.Background()); password != "rotated" {
+		t.Errorf("got password %q after rotating the file", password)
+	}
+	if _, _, err := FileCredentials("app", filepath.Join(dir, "missing")).Credentials(context.Background()); err == nil {
+		t.Errorf("a missing file should be an error")
+	}
+}
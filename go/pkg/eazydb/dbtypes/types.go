@@ -9,15 +9,16 @@ import (
 type ValType string
 
 const (
-	TEXT     ValType = "TEXT"
-	INT      ValType = "INT"
-	FLOAT    ValType = "FLOAT"
-	DOUBLE   ValType = "DOUBLE"
-	DATETIME ValType = "DATETIME"
-	BOOL     ValType = "BOOL"
-	BLOB     ValType = "BLOB"
-	SERIAL   ValType = "SERIAL"
-	NONE     ValType = "NONE"
+	TEXT      ValType = "TEXT"
+	INT       ValType = "INT"
+	FLOAT     ValType = "FLOAT"
+	DOUBLE    ValType = "DOUBLE"
+	DATETIME  ValType = "DATETIME"
+	TIMESTAMP ValType = "TIMESTAMP"
+	BOOL      ValType = "BOOL"
+	BLOB      ValType = "BLOB"
+	SERIAL    ValType = "SERIAL"
+	NONE      ValType = "NONE"
 )
 
 func ToSQL(gotype reflect.Kind) (ValType, error) {
@@ -29,6 +30,8 @@ func ToSQL(gotype reflect.Kind) (ValType, error) {
 		return TEXT, nil
 	case reflect.Float32, reflect.Float64:
 		return DOUBLE, nil
+	case reflect.Bool:
+		return BOOL, nil
 	case reflect.Struct:
 		if gotype == reflect.TypeOf(time.Time{}).Kind() {
 			return DATETIME, nil
@@ -39,6 +42,18 @@ func ToSQL(gotype reflect.Kind) (ValType, error) {
 	return NONE, fmt.Errorf("%v is support or not yet supported", gotype.String())
 }
 
+// ToSQLType is like ToSQL but can tell time.Time apart from other structs.
+// Postgres has no DATETIME, so times are TIMESTAMP columns
+func ToSQLType(gotype reflect.Type) (ValType, error) {
+	if gotype == reflect.TypeOf(time.Time{}) {
+		return TIMESTAMP, nil
+	}
+	if gotype.Kind() == reflect.Struct {
+		return NONE, fmt.Errorf("%v is not supported", gotype.String())
+	}
+	return ToSQL(gotype.Kind())
+}
+
 type Key struct {
 	Name    string
 	SQLType ValType
@@ -0,0 +1,114 @@
+package eazydb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// Errors returned by queries can be matched with errors.Is, eg: errors.Is(err, eazydb.ErrDuplicate)
+var (
+	// ErrNoRows is returned by Model.Find and by queries using ErrIfNoneReturned.
+	// It also matches sql.ErrNoRows
+	ErrNoRows = errors.New("no rows")
+	// ErrDuplicate is a unique or primary key violation
+	ErrDuplicate = errors.New("duplicate key")
+	// ErrForeignKey is a foreign key violation
+	ErrForeignKey = errors.New("foreign key violation")
+	// ErrCheck is a check constraint violation
+	ErrCheck = errors.New("check constraint violation")
+	// ErrConflict is a serialization failure or deadlock, the transaction can be retried
+	ErrConflict = errors.New("serialization conflict")
+)
+
+// postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+var pqErrors = map[pq.ErrorCode]error{
+	"23505": ErrDuplicate,
+	"23503": ErrForeignKey,
+	"23514": ErrCheck,
+	"40001": ErrConflict,
+	"40P01": ErrConflict,
+}
+
+// QueryError is returned when a query fails in the database
+type QueryError struct {
+	Table string
+	Op    dbtypes.QueryOperation
+	Query string
+	// set when the database reports which constraint or column caused the error
+	Constraint string
+	Column     string
+	// the error returned by the driver
+	Err error
+	// one of the sentinel errors, nil if the error could not be classified
+	kind error
+}
+
+func (e *QueryError) Error() string {
+	op := strings.Fields(string(e.Op))
+	if len(op) == 0 {
+		return fmt.Sprintf("query on %s failed: %v", e.Table, e.Err)
+	}
+	return fmt.Sprintf("%s on %s failed: %v", op[0], e.Table, e.Err)
+}
+
+func (e *QueryError) Unwrap() []error {
+	if e.kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.kind, e.Err}
+}
+
+// queryError wraps an error from running the query, classifying driver errors
+func (q *Query) queryError(err error, query string) error {
+	if err == nil {
+		return nil
+	}
+	var qerr *QueryError
+	if errors.As(err, &qerr) {
+		return err
+	}
+	qerr = &QueryError{Table: q.name, Op: q.op, Query: query, Err: err}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		qerr.kind = pqErrors[pqErr.Code]
+		qerr.Constraint = pqErr.Constraint
+		qerr.Column = pqErr.Column
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		qerr.kind = ErrNoRows
+	}
+	return qerr
+}
+
+func noRowsError(table string, op dbtypes.QueryOperation, query string, detail string) error {
+	return &QueryError{
+		Table: table,
+		Op:    op,
+		Query: query,
+		Err:   fmt.Errorf("%s: %w", detail, sql.ErrNoRows),
+		kind:  ErrNoRows,
+	}
+}
+
+// checkNoneReturned enforces ErrIfNoneReturned. Get and Returning need a row back,
+// Update and Delete need to change a row
+func (q *Query) checkNoneReturned(metadata *Metadata) error {
+	if !q.errIfNoneReturned || metadata == nil {
+		return nil
+	}
+	switch {
+	case q.op == dbtypes.SELECT || q.hasReturning():
+		if metadata.RowsReturned == 0 {
+			return noRowsError(q.name, q.op, metadata.Query, "no rows were returned")
+		}
+	case metadata.RowsAffected == 0:
+		return noRowsError(q.name, q.op, metadata.Query, "no rows were changed")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package eazydb
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestQueryErrorClassifiesDriverErrors(t *testing.T) {
+	tests := []struct {
+		code pq.ErrorCode
+		kind error
+	}{
+		{"23505", ErrDuplicate},
+		{"23503", ErrForeignKey},
+		{"23514", ErrCheck},
+		{"40001", ErrConflict},
+		{"40P01", ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			c, fake := newTestClient(t)
+			pqErr := &pq.Error{Code: tt.code, Constraint: "users_email_key", Column: "email"}
+			fake.respond = func(string) fakeResult { return fakeResult{err: pqErr} }
+
+			_, err := c.Table("users").Add(upsertUser{Name: "Mat"}).Exec()
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("got error %v, want it to match %v", err, tt.kind)
+			}
+			var qerr *QueryError
+			if !errors.As(err, &qerr) {
+				t.Fatalf("got error %T, want a QueryError", err)
+			}
+			if qerr.Table != "users" || qerr.Constraint != "users_email_key" || qerr.Column != "email" || qerr.Query == "" {
+				t.Errorf("got %+v", qerr)
+			}
+			if !errors.As(err, &pqErr) {
+				t.Errorf("the driver error should still be reachable")
+			}
+		})
+	}
+}
+
+func TestQueryErrorMessage(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(string) fakeResult { return fakeResult{err: errors.New("boom")} }
+
+	_, err := c.Table("users").Delete().Where(*Int("id").Equals(1)).Exec()
+	if err == nil || err.Error() != "DELETE on users failed: boom" {
+		t.Fatalf("got error %v", err)
+	}
+	for _, kind := range []error{ErrDuplicate, ErrForeignKey, ErrCheck, ErrConflict, ErrNoRows} {
+		if errors.Is(err, kind) {
+			t.Errorf("an unclassified error matched %v", kind)
+		}
+	}
+}
+
+func TestErrIfNoneReturned(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(query string) fakeResult {
+		if query[0] == 'S' {
+			return fakeResult{columns: []string{"name"}}
+		}
+		return fakeResult{affected: 0}
+	}
+
+	var users []upsertUser
+	_, err := c.Table("users").Get(upsertUser{}).ErrIfNoneReturned().Exec(&users)
+	if !errors.Is(err, ErrNoRows) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got error %v from Get", err)
+	}
+	_, err = c.Table("users").Delete().Where(*Int("id").Equals(1)).ErrIfNoneReturned().Exec()
+	if !errors.Is(err, ErrNoRows) {
+		t.Errorf("got error %v from Delete", err)
+	}
+	if _, err := c.Table("users").Delete().Where(*Int("id").Equals(1)).Exec(); err != nil {
+		t.Errorf("without ErrIfNoneReturned changing no rows is fine, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package eazydb
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// Expression is raw SQL used in place of a value. It is not quoted or escaped,
+// so never build one from user input
+type Expression struct {
+	sql string
+}
+
+// Expr uses raw SQL as a value
+//
+//	Update().Set("days_present", eazydb.Expr("days_present + 1"))
+func Expr(sql string) Expression {
+	return Expression{sql: sql}
+}
+
+// Column references another column of the same row
+//
+//	Update().Set("previous_email", eazydb.Column("email"))
+func Column(name string) Expression {
+	return Expression{sql: name}
+}
+
+// Now is the time the statement runs, as seen by the database
+func Now() Expression {
+	return Expression{sql: "CURRENT_TIMESTAMP"}
+}
+
+// Set assigns a value or Expression to a column. The database evaluates expressions
+// against the current row, so they are safe to run concurrently
+func (q *Query) Set(column string, val interface{}) *Query {
+	if q.op != dbtypes.UPDATE {
+		q.err = fmt.Errorf("Set can only be used with Update, table operation is %v", q.op)
+		return q
+	}
+	q.sets = mergeSets(q.sets, []field{{Name: column, Val: val}})
+	return q
+}
+
+// Inc adds to a numeric column, use a negative amount to decrement. The amount must be
+// an integer or float
+//
+//	UPDATE users SET days_present = days_present + 1
+func (q *Query) Inc(column string, amount interface{}) *Query {
+	n, err := formatNumber(amount)
+	if err != nil {
+		q.err = fmt.Errorf("Inc requires a numeric amount: %w", err)
+		return q
+	}
+	return q.Set(column, Expr(fmt.Sprintf("%s + %s", column, n)))
+}
+
+// formatNumber renders integers and finite floats, anything else is rejected so it
+// can't be used to inject SQL
+func formatNumber(val interface{}) (string, error) {
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(v.Float()) || math.IsInf(v.Float(), 0) {
+			return "", fmt.Errorf("got %v", v.Float())
+		}
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("got %T", val)
+}
+
+// SetNow sets a column to the current time
+func (q *Query) SetNow(column string) *Query {
+	return q.Set(column, Now())
+}
+
+// mergeSets adds the sets to fields, replacing any field with the same name
+func mergeSets(fields []field, sets []field) []field {
+	merged := append([]field{}, fields...)
+	for _, set := range sets {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == set.Name {
+				merged[i] = set
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, set)
+		}
+	}
+	return merged
+}
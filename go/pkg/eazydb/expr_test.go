@@ -0,0 +1,68 @@
+package eazydb
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type cents int
+
+func TestUpdateExpressions(t *testing.T) {
+	c, _ := newTestClient(t)
+	where := *Int("id").Equals(1)
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  string
+		args  []interface{}
+	}{
+		{"inc", c.Table("users").Update().Inc("days_present", 1).Where(where), "UPDATE users SET days_present = days_present + 1 WHERE id = $1", []interface{}{1}},
+		{"dec", c.Table("users").Update().Inc("balance", cents(-10)).Where(where), "UPDATE users SET balance = balance + -10 WHERE id = $1", []interface{}{1}},
+		{"float", c.Table("users").Update().Inc("score", float32(0.1)).Where(where), "UPDATE users SET score = score + 0.1 WHERE id = $1", []interface{}{1}},
+		{"column", c.Table("users").Update().Set("previous_email", Column("email")).Where(where), "UPDATE users SET previous_email = email WHERE id = $1", []interface{}{1}},
+		{"now", c.Table("users").Update().SetNow("seen_at").Where(where), "UPDATE users SET seen_at = CURRENT_TIMESTAMP WHERE id = $1", []interface{}{1}},
+		{"arithmetic", c.Table("users").Update().Set("total", Expr("price * quantity")).Where(where), "UPDATE users SET total = price * quantity WHERE id = $1", []interface{}{1}},
+		{
+			name:  "sets override struct fields",
+			query: c.Table("users").Update(updateUser{Name: "Mat", Age: 3}).Set("age", Expr("age + 1")).Where(where),
+			want:  "UPDATE users SET name = $1, age = age + 1 WHERE id = $2",
+			args:  []interface{}{"Mat", 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.query.Dry().Exec()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Query != tt.want {
+				t.Errorf("got  %s\nwant %s", metadata.Query, tt.want)
+			}
+			if !reflect.DeepEqual(metadata.Args, tt.args) {
+				t.Errorf("got args %v, want %v", metadata.Args, tt.args)
+			}
+		})
+	}
+}
+
+func TestIncRejectsNonNumbers(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	for _, amount := range []interface{}{"1; DROP TABLE users", Expr("1"), math.NaN(), nil} {
+		_, err := c.Table("users").Update().Inc("balance", amount).Where(*Int("id").Equals(1)).Dry().Exec()
+		if err == nil || !strings.Contains(err.Error(), "Inc requires a numeric amount") {
+			t.Errorf("got error %v for amount %#v", err, amount)
+		}
+	}
+}
+
+func TestSetOnlyOnUpdate(t *testing.T) {
+	c, _ := newTestClient(t)
+	_, err := c.Table("users").Get(updateUser{}).Set("age", 1).Dry().Exec()
+	if err == nil || !strings.Contains(err.Error(), "Set can only be used with Update") {
+		t.Fatalf("got error %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDB is a database/sql driver that records every statement it is sent and answers with
+// results chosen by the test, so the SQL built by the client can be checked without postgres
+type fakeDB struct {
+	mu    sync.Mutex
+	stmts []string
+	// respond answers each statement as it is recorded, along with its args.
+	// defaultResult is used when nil
+	respond func(query string) fakeResult
+	pingErr error
+}
+
+type fakeResult struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// defaultResult affects one row per value list and returns no rows
+func defaultResult(query string) fakeResult {
+	return fakeResult{affected: int64(strings.Count(query, "), (") + 1)}
+}
+
+var fakeDBs = struct {
+	sync.Mutex
+	dbs map[string]*fakeDB
+}{dbs: make(map[string]*fakeDB)}
+
+func init() {
+	sql.Register("eazydb-fake", fakeDriver{})
+}
+
+// newFakeDB opens a pool on a new fakeDB
+func newFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{}
+	fakeDBs.Lock()
+	name := fmt.Sprintf("%s/%d", t.Name(), len(fakeDBs.dbs))
+	fakeDBs.dbs[name] = fake
+	fakeDBs.Unlock()
+
+	db, err := sql.Open("eazydb-fake", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+// newTestClient returns a client set up the way NewClient sets one up, backed by a fakeDB
+func newTestClient(t *testing.T) (*Client, *fakeDB) {
+	t.Helper()
+	db, fake := newFakeDB(t)
+	return &Client{
+		DB:          db,
+		log:         newQueryLogger(initLogger(nil, false), QueryLog{}),
+		dbType:      POSTGRES,
+		keys:        newKeyRegistry(),
+		softDeletes: newTableSet(),
+	}, fake
+}
+
+// statements returns every statement run so far, each followed by its args if it has any
+func (f *fakeDB) statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.stmts...)
+}
+
+// queries returns the statements run so far, leaving out BEGIN, COMMIT and ROLLBACK
+func (f *fakeDB) queries() []string {
+	var queries []string
+	for _, stmt := range f.statements() {
+		if stmt != "BEGIN" && stmt != "COMMIT" && stmt != "ROLLBACK" {
+			queries = append(queries, stmt)
+		}
+	}
+	return queries
+}
+
+func (f *fakeDB) record(stmt string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stmts = append(f.stmts, stmt)
+}
+
+func (f *fakeDB) result(query string, args []driver.Value) fakeResult {
+	stmt := query
+	if len(args) > 0 {
+		stmt += " " + fmt.Sprint(args)
+	}
+	f.record(stmt)
+
+	f.mu.Lock()
+	respond := f.respond
+	f.mu.Unlock()
+	if respond == nil {
+		return defaultResult(query)
+	}
+	return respond(stmt)
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBs.Lock()
+	defer fakeDBs.Unlock()
+	fake, ok := fakeDBs.dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("no fake database %s", name)
+	}
+	return &fakeConn{db: fake}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN")
+	return &fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) Ping(context.Context) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return c.db.pingErr
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.record("COMMIT")
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK")
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res := s.db.result(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return driver.RowsAffected(res.affected), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res := s.db.result(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &fakeRows{columns: res.columns, rows: res.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
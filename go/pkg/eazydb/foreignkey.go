@@ -0,0 +1,234 @@
+package eazydb
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ForeignKey links columns of a table to the key of another table
+type ForeignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   FK_ACTION
+	OnUpdate   FK_ACTION
+}
+
+// ForeignKey starts a foreign key on the given columns, follow with References
+//
+//	NewTable("orders").Fields(Order{}).ForeignKey("user_id").References("users", "id").OnDelete(eazydb.CASCADE)
+func (t *TableInstance) ForeignKey(columns ...string) *TableInstance {
+	if len(columns) == 0 {
+		t.err = fmt.Errorf("ForeignKey requires at least one column")
+		return t
+	}
+	t.foreignKeys = append(t.foreignKeys, ForeignKey{Table: t.name, Columns: columns})
+	return t
+}
+
+// References sets the table and columns the last ForeignKey points at
+func (t *TableInstance) References(table string, columns ...string) *TableInstance {
+	fk := t.lastForeignKey("References")
+	if fk == nil {
+		return t
+	}
+	if len(columns) != len(fk.Columns) {
+		t.err = fmt.Errorf("foreign key on %v references %v columns of %s, expected %v", fk.Columns, len(columns), table, len(fk.Columns))
+		return t
+	}
+	fk.RefTable = table
+	fk.RefColumns = columns
+	return t
+}
+
+// OnDelete sets what happens to rows of this table when the referenced row is deleted
+func (t *TableInstance) OnDelete(action FK_ACTION) *TableInstance {
+	if fk := t.lastForeignKey("OnDelete"); fk != nil {
+		fk.OnDelete = action
+	}
+	return t
+}
+
+// OnUpdate sets what happens to rows of this table when the referenced key changes
+func (t *TableInstance) OnUpdate(action FK_ACTION) *TableInstance {
+	if fk := t.lastForeignKey("OnUpdate"); fk != nil {
+		fk.OnUpdate = action
+	}
+	return t
+}
+
+func (t *TableInstance) lastForeignKey(method string) *ForeignKey {
+	if len(t.foreignKeys) == 0 {
+		t.err = fmt.Errorf("%s requires ForeignKey to be called first", method)
+		return nil
+	}
+	return &t.foreignKeys[len(t.foreignKeys)-1]
+}
+
+// constructForeignKeys returns the foreign keys from the builder and from `db:",references=users(id)"` tags
+func (t *TableInstance) constructForeignKeys(fields []field) ([]ForeignKey, error) {
+	var fks []ForeignKey
+	for _, f := range fields {
+		if f.references == nil {
+			continue
+		}
+		fk := *f.references
+		fk.Table = t.name
+		fk.Columns = []string{f.Name}
+		fks = append(fks, fk)
+	}
+	fks = append(fks, t.foreignKeys...)
+
+	for i := range fks {
+		if fks[i].RefTable == "" {
+			return nil, fmt.Errorf("foreign key on %v is missing References", fks[i].Columns)
+		}
+		if fks[i].Name == "" {
+			fks[i].Name = fmt.Sprintf("%s_%s_fkey", t.name, strings.Join(fks[i].Columns, "_"))
+		}
+	}
+	return fks, nil
+}
+
+// Produces a constraint like below
+//
+//	CONSTRAINT orders_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+func (fk *ForeignKey) constructQuery() string {
+	stmt := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		fk.Name, strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+	if fk.OnDelete != "" {
+		stmt += fmt.Sprintf(" ON DELETE %s", fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		stmt += fmt.Sprintf(" ON UPDATE %s", fk.OnUpdate)
+	}
+	return stmt
+}
+
+// sameTarget reports whether both keys link the same columns to the same columns
+func (fk *ForeignKey) sameTarget(other ForeignKey) bool {
+	return strings.Join(fk.Columns, ",") == strings.Join(other.Columns, ",") &&
+		fk.RefTable == other.RefTable &&
+		strings.Join(fk.RefColumns, ",") == strings.Join(other.RefColumns, ",")
+}
+
+// parseReference reads a tag option like users(id)
+func parseReference(ref string) (*ForeignKey, error) {
+	open := strings.Index(ref, "(")
+	if open <= 0 || !strings.HasSuffix(ref, ")") {
+		return nil, fmt.Errorf("references must look like table(column), got %v", ref)
+	}
+	return &ForeignKey{
+		RefTable:   ref[:open],
+		RefColumns: []string{ref[open+1 : len(ref)-1]},
+	}, nil
+}
+
+// addNewForeignKeys adds the declared foreign keys that the table does not have yet
+func (t *TableInstance) addNewForeignKeys(metadata *Metadata, fields []field) error {
+	declared, err := t.constructForeignKeys(fields)
+	if err != nil || len(declared) == 0 {
+		return err
+	}
+
+	existing, err := foreignKeys(t.db, t.name)
+	if err != nil {
+		return err
+	}
+
+	var adds []string
+	for _, fk := range declared {
+		found := false
+		for _, ex := range existing {
+			if fk.sameTarget(ex) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			adds = append(adds, " ADD "+fk.constructQuery())
+		}
+	}
+	if len(adds) == 0 {
+		return nil
+	}
+
+	stmt := fmt.Sprintf("ALTER TABLE %s%s;", t.name, strings.Join(adds, ","))
+	if err := t.exec(metadata, "ALTER", stmt); err != nil {
+		return err
+	}
+	metadata.Query += " " + stmt
+	return nil
+}
+
+// ForeignKeys returns the foreign keys declared on a table
+func (c *Client) ForeignKeys(table string) ([]ForeignKey, error) {
+	return foreignKeys(c.DB, table)
+}
+
+func foreignKeys(db *sql.DB, table string) ([]ForeignKey, error) {
+	var fks []ForeignKey
+	query := `
+		SELECT con.conname,
+			array_to_string(ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.n
+			), ','),
+			ref.relname,
+			array_to_string(ARRAY(
+				SELECT a.attname
+				FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.n
+			), ','),
+			con.confdeltype, con.confupdtype
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_class ref ON ref.oid = con.confrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE con.contype = 'f' AND t.relname = $1 AND n.nspname = 'public'
+		ORDER BY con.conname;
+	`
+
+	rows, err := db.Query(query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fk := ForeignKey{Table: table}
+		var columns, refColumns, onDelete, onUpdate string
+		err := rows.Scan(&fk.Name, &columns, &fk.RefTable, &refColumns, &onDelete, &onUpdate)
+		if err != nil {
+			return nil, err
+		}
+		fk.Columns = strings.Split(columns, ",")
+		fk.RefColumns = strings.Split(refColumns, ",")
+		fk.OnDelete = fkAction(onDelete)
+		fk.OnUpdate = fkAction(onUpdate)
+		fks = append(fks, fk)
+	}
+
+	return fks, rows.Err()
+}
+
+// fkAction maps the action codes postgres stores in pg_constraint
+func fkAction(code string) FK_ACTION {
+	switch code {
+	case "r":
+		return RESTRICT
+	case "c":
+		return CASCADE
+	case "n":
+		return SET_NULL
+	case "d":
+		return SET_DEFAULT
+	}
+	return NO_ACTION
+}
@@ -0,0 +1,180 @@
+package eazydb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// BeforeInsert is called on each row passed to Add before the query is built.
+// Like the other hooks it gets the context passed to ExecContext, an error aborts the operation,
+// and it is not called on Dry runs
+//
+//	func (u *User) BeforeInsert(ctx context.Context) error {
+//		u.Email = strings.ToLower(u.Email)
+//		return nil
+//	}
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInsert is called once the rows are written, with any Returning values read back.
+// An error rolls the insert back, except for batches already committed by Parallel
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdate is called on the struct passed to Update before the query is built
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterFind is called on each row read by Get, after relations are preloaded
+type AfterFinder interface {
+	AfterFind(ctx context.Context) error
+}
+
+// BeforeDelete is called on the row passed to Delete, eg: Delete(&user)
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// hook is a model hook and how to call it on a row
+type hook struct {
+	name  string
+	iface reflect.Type
+	call  func(ctx context.Context, row interface{}) error
+}
+
+var (
+	beforeInsert = hook{"BeforeInsert", reflect.TypeOf((*BeforeInserter)(nil)).Elem(), func(ctx context.Context, row interface{}) error {
+		return row.(BeforeInserter).BeforeInsert(ctx)
+	}}
+	afterInsert = hook{"AfterInsert", reflect.TypeOf((*AfterInserter)(nil)).Elem(), func(ctx context.Context, row interface{}) error {
+		return row.(AfterInserter).AfterInsert(ctx)
+	}}
+	beforeUpdate = hook{"BeforeUpdate", reflect.TypeOf((*BeforeUpdater)(nil)).Elem(), func(ctx context.Context, row interface{}) error {
+		return row.(BeforeUpdater).BeforeUpdate(ctx)
+	}}
+	afterFind = hook{"AfterFind", reflect.TypeOf((*AfterFinder)(nil)).Elem(), func(ctx context.Context, row interface{}) error {
+		return row.(AfterFinder).AfterFind(ctx)
+	}}
+	beforeDelete = hook{"BeforeDelete", reflect.TypeOf((*BeforeDeleter)(nil)).Elem(), func(ctx context.Context, row interface{}) error {
+		return row.(BeforeDeleter).BeforeDelete(ctx)
+	}}
+)
+
+// rowType returns the struct type of rows, which is a struct, a pointer to one or a slice of either
+func rowType(rows interface{}) reflect.Type {
+	if rows == nil {
+		return nil
+	}
+	t := reflect.TypeOf(rows)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// implements reports whether a pointer to the row type implements the hook
+func implements(rows interface{}, h hook) bool {
+	t := rowType(rows)
+	return t != nil && reflect.PointerTo(t).Implements(h.iface)
+}
+
+// eachRow calls fn with each struct in rows, which is a struct, a pointer to one or a slice
+// of either, or a pointer to that slice. A struct passed by value is copied so fn can
+// change it, and the copy is returned in place of rows
+func eachRow(rows interface{}, fn func(row reflect.Value) error) (interface{}, error) {
+	visit := func(row reflect.Value) error {
+		for row.Kind() == reflect.Ptr || row.Kind() == reflect.Interface {
+			if row.IsNil() {
+				return nil
+			}
+			row = row.Elem()
+		}
+		if row.Kind() != reflect.Struct {
+			return nil
+		}
+		return fn(row)
+	}
+
+	v := reflect.ValueOf(rows)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := visit(v.Index(i)); err != nil {
+				return rows, err
+			}
+		}
+	case reflect.Ptr:
+		return rows, visit(v)
+	case reflect.Struct:
+		row := reflect.New(v.Type()).Elem()
+		row.Set(v)
+		if err := visit(row); err != nil {
+			return rows, err
+		}
+		return row.Interface(), nil
+	}
+	return rows, nil
+}
+
+// callHook runs the hook on every row that implements it
+func (q *Query) callHook(rows interface{}, h hook) (interface{}, error) {
+	if !implements(rows, h) {
+		return rows, nil
+	}
+	rows, err := eachRow(rows, func(row reflect.Value) error {
+		return h.call(q.ctx, row.Addr().Interface())
+	})
+	if err != nil {
+		return rows, fmt.Errorf("%s hook failed on %s: %w", h.name, q.name, err)
+	}
+	return rows, nil
+}
+
+// hasAfterInsert reports whether the rows passed to Add have an AfterInsert hook
+func (q *Query) hasAfterInsert() bool {
+	return q.op == dbtypes.INSERT && implements(q.fields, afterInsert)
+}
+
+// beforeHooks runs BeforeInsert, BeforeUpdate or BeforeDelete on the rows passed to the query
+func (q *Query) beforeHooks() error {
+	hooks := map[dbtypes.QueryOperation]hook{
+		dbtypes.INSERT: beforeInsert,
+		dbtypes.UPDATE: beforeUpdate,
+		dbtypes.DELETE: beforeDelete,
+	}
+	h, ok := hooks[q.op]
+	if !ok || q.fields == nil {
+		return nil
+	}
+
+	rows, err := q.callHook(q.fields, h)
+	if err != nil {
+		return err
+	}
+	q.fields = rows
+	return nil
+}
+
+// afterHooks runs AfterInsert on the rows passed to Add, or AfterFind on the rows read into target
+func (q *Query) afterHooks(target interface{}) error {
+	var err error
+	switch q.op {
+	case dbtypes.INSERT:
+		_, err = q.callHook(q.fields, afterInsert)
+	case dbtypes.SELECT:
+		_, err = q.callHook(target, afterFind)
+	}
+	return err
+}
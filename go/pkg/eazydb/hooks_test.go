@@ -0,0 +1,123 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type hookedUser struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	calls []string
+	fail  error
+	// failAfter only fails AfterInsert
+	failAfter error
+}
+
+func (u *hookedUser) BeforeInsert(ctx context.Context) error {
+	u.calls = append(u.calls, "BeforeInsert")
+	u.Email = strings.ToLower(u.Email)
+	return u.fail
+}
+
+func (u *hookedUser) AfterInsert(ctx context.Context) error {
+	u.calls = append(u.calls, "AfterInsert")
+	return u.failAfter
+}
+
+func (u *hookedUser) BeforeUpdate(ctx context.Context) error {
+	u.calls = append(u.calls, "BeforeUpdate")
+	return u.fail
+}
+
+func (u *hookedUser) AfterFind(ctx context.Context) error {
+	u.Phone = "***"
+	return nil
+}
+
+func (u *hookedUser) BeforeDelete(ctx context.Context) error {
+	u.calls = append(u.calls, "BeforeDelete")
+	return u.fail
+}
+
+func TestInsertHooks(t *testing.T) {
+	c, fake := newTestClient(t)
+	users := []hookedUser{{Email: "MAT@X.IO"}, {Email: "Ann@x.io"}}
+
+	if _, err := c.Table("users").Add(users).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range users {
+		if !reflect.DeepEqual(u.calls, []string{"BeforeInsert", "AfterInsert"}) {
+			t.Errorf("got calls %v", u.calls)
+		}
+	}
+	want := []string{"BEGIN", "INSERT INTO users (email) VALUES ($1), ($2); [mat@x.io ann@x.io]", "COMMIT"}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestAfterInsertErrorRollsBack(t *testing.T) {
+	c, fake := newTestClient(t)
+	errNo := errors.New("no")
+
+	_, err := c.Table("users").Add(&hookedUser{Email: "mat@x.io", failAfter: errNo}).Exec()
+	if !errors.Is(err, errNo) {
+		t.Fatalf("got error %v, want it to wrap %v", err, errNo)
+	}
+	want := []string{"BEGIN", "INSERT INTO users (email) VALUES ($1); [mat@x.io]", "ROLLBACK"}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestHooksAbortAndFind(t *testing.T) {
+	c, fake := newTestClient(t)
+	errNo := errors.New("no")
+
+	user := &hookedUser{Email: "mat@x.io", fail: errNo}
+	if _, err := c.Table("users").Delete(user).Where(*String("email").Equals(user.Email)).Exec(); !errors.Is(err, errNo) {
+		t.Errorf("got error %v from BeforeDelete", err)
+	}
+	if _, err := c.Table("users").Update(user).Where(*String("email").Equals(user.Email)).Exec(); !errors.Is(err, errNo) {
+		t.Errorf("got error %v from BeforeUpdate", err)
+	}
+	if got := fake.statements(); len(got) != 0 {
+		t.Errorf("aborted queries reached the database: %q", got)
+	}
+
+	fake.respond = func(string) fakeResult {
+		return fakeResult{columns: []string{"email", "phone"}, rows: [][]driver.Value{{"mat@x.io", "0400 000 000"}}}
+	}
+	var found []hookedUser
+	if _, err := c.Table("users").Get(hookedUser{}).Exec(&found); err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Phone != "***" {
+		t.Errorf("AfterFind did not run, got %+v", found)
+	}
+}
+
+func TestDryRunSkipsHooks(t *testing.T) {
+	c, _ := newTestClient(t)
+	user := &hookedUser{Email: "MAT@X.IO", fail: errors.New("no")}
+
+	metadata, err := c.Table("users").Add(user).Dry().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(user.calls) != 0 || user.Email != "MAT@X.IO" {
+		t.Errorf("hooks ran on a dry run: %v", user.calls)
+	}
+	if want := "INSERT INTO users (email) VALUES ($1);"; metadata.Query != want {
+		t.Errorf("got  %s\nwant %s", metadata.Query, want)
+	}
+	if want := []interface{}{"MAT@X.IO"}; !reflect.DeepEqual(metadata.Args, want) {
+		t.Errorf("got args %v, want %v", metadata.Args, want)
+	}
+}
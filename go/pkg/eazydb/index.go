@@ -0,0 +1,235 @@
+package eazydb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type IndexInstance struct {
+	db           *sql.DB
+	name         string
+	table        string
+	columns      []string
+	conditions   []Condition
+	unique       bool
+	concurrently bool
+	dryrun       bool
+	err          error
+	log          *queryLogger
+}
+
+// Index describes an index found on a table
+type Index struct {
+	Name       string
+	Table      string
+	Columns    []string
+	Unique     bool
+	Primary    bool
+	Where      string
+	Definition string
+}
+
+func (c *Client) NewIndex(name string) *IndexInstance {
+	var err error = nil
+	if name == "" {
+		err = errors.New("an index name is required")
+	}
+	return &IndexInstance{
+		db:   c.DB,
+		name: name,
+		err:  err,
+		log:  c.log,
+	}
+}
+
+func (i *IndexInstance) On(table string) *IndexInstance {
+	i.table = table
+	return i
+}
+
+func (i *IndexInstance) Columns(columns ...string) *IndexInstance {
+	i.columns = append(i.columns, columns...)
+	return i
+}
+
+func (i *IndexInstance) Unique() *IndexInstance {
+	i.unique = true
+	return i
+}
+
+// Where makes a partial index that only covers rows matching the conditions
+func (i *IndexInstance) Where(conditions ...Condition) *IndexInstance {
+	i.conditions = append(i.conditions, conditions...)
+	return i
+}
+
+// Concurrently builds the index without locking the table against writes.
+// It takes longer and cannot be run inside a transaction
+func (i *IndexInstance) Concurrently() *IndexInstance {
+	i.concurrently = true
+	return i
+}
+
+func (i *IndexInstance) Dry() *IndexInstance {
+	i.dryrun = true
+	return i
+}
+
+func (i *IndexInstance) Exec() (*Metadata, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+
+	var metadata *Metadata = &Metadata{}
+	var err error
+	metadata.Query, err = i.constructQuery()
+	if err != nil {
+		return nil, fmt.Errorf("could not construct query: %v", err)
+	}
+	if i.dryrun {
+		return metadata, nil
+	}
+
+	now := time.Now()
+	_, err = i.db.Exec(metadata.Query)
+	metadata.Duration = time.Since(now)
+	i.log.query(queryEntry{table: i.table, op: "CREATE", query: metadata.Query, duration: metadata.Duration, err: err})
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS users_email_idx ON users (email) WHERE active = true;
+func (i *IndexInstance) constructQuery() (string, error) {
+	if i.table == "" {
+		return "", errors.New("a table is required, set it with On")
+	}
+	if len(i.columns) == 0 {
+		return "", errors.New("at least one column is required, set them with Columns")
+	}
+
+	stmt := "CREATE"
+	if i.unique {
+		stmt += " UNIQUE"
+	}
+	stmt += " INDEX"
+	if i.concurrently {
+		stmt += " CONCURRENTLY"
+	}
+	stmt += fmt.Sprintf(" IF NOT EXISTS %s ON %s (%s)", i.name, i.table, strings.Join(i.columns, ", "))
+
+	// DDL can't take parameters, so the values are written into the statement
+	stmt += whereClause(&binder{inline: true}, i.conditions)
+	stmt += ";"
+	return stmt, nil
+}
+
+type DropIndexInstance struct {
+	db           *sql.DB
+	name         string
+	ifExists     bool
+	concurrently bool
+	dryrun       bool
+	err          error
+	log          *queryLogger
+}
+
+func (c *Client) DropIndex(name string) *DropIndexInstance {
+	var err error = nil
+	if name == "" {
+		err = errors.New("an index name is required")
+	}
+	return &DropIndexInstance{
+		db:   c.DB,
+		name: name,
+		err:  err,
+		log:  c.log,
+	}
+}
+
+func (d *DropIndexInstance) IfExists() *DropIndexInstance {
+	d.ifExists = true
+	return d
+}
+
+func (d *DropIndexInstance) Concurrently() *DropIndexInstance {
+	d.concurrently = true
+	return d
+}
+
+func (d *DropIndexInstance) Dry() *DropIndexInstance {
+	d.dryrun = true
+	return d
+}
+
+func (d *DropIndexInstance) Exec() (*Metadata, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var metadata *Metadata = &Metadata{}
+	metadata.Query = "DROP INDEX"
+	if d.concurrently {
+		metadata.Query += " CONCURRENTLY"
+	}
+	if d.ifExists {
+		metadata.Query += " IF EXISTS"
+	}
+	metadata.Query += fmt.Sprintf(" %s;", d.name)
+	if d.dryrun {
+		return metadata, nil
+	}
+
+	now := time.Now()
+	_, err := d.db.Exec(metadata.Query)
+	metadata.Duration = time.Since(now)
+	d.log.query(queryEntry{op: "DROP", query: metadata.Query, duration: metadata.Duration, err: err})
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// Indexes returns the indexes on a table, including the one backing the primary key
+func (c *Client) Indexes(table string) ([]Index, error) {
+	var indexes []Index
+	query := `
+		SELECT i.relname, ix.indisunique, ix.indisprimary,
+			array_to_string(ARRAY(
+				SELECT pg_get_indexdef(ix.indexrelid, k + 1, true)
+				FROM generate_subscripts(ix.indkey, 1) AS k
+				ORDER BY k
+			), ','),
+			COALESCE(pg_get_expr(ix.indpred, ix.indrelid), ''),
+			pg_get_indexdef(ix.indexrelid)
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE t.relname = $1 AND n.nspname = 'public'
+		ORDER BY i.relname;
+	`
+
+	rows, err := c.Query(query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		index := Index{Table: table}
+		var columns string
+		err := rows.Scan(&index.Name, &index.Unique, &index.Primary, &columns, &index.Where, &index.Definition)
+		if err != nil {
+			return nil, err
+		}
+		index.Columns = strings.Split(columns, ",")
+		indexes = append(indexes, index)
+	}
+
+	return indexes, rows.Err()
+}
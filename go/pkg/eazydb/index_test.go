@@ -0,0 +1,119 @@
+package eazydb
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type indexedUser struct {
+	Email    string `json:"email" db:",uniqueindex"`
+	TenantID int    `json:"tenant_id" db:",index=users_tenant_name_idx"`
+	Name     string `json:"name" db:",index=users_tenant_name_idx"`
+	Age      int    `json:"age"`
+}
+
+func TestIndexQuery(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	tests := []struct {
+		name  string
+		index *IndexInstance
+		want  string
+	}{
+		{"plain", c.NewIndex("users_age_idx").On("users").Columns("age"), "CREATE INDEX IF NOT EXISTS users_age_idx ON users (age);"},
+		{
+			name:  "unique partial concurrently",
+			index: c.NewIndex("users_email_idx").On("users").Columns("email").Unique().Concurrently().Where(*Int("age").GreaterThan(17)),
+			want:  "CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS users_email_idx ON users (email) WHERE age > 17;",
+		},
+		{"composite", c.NewIndex("users_ab_idx").On("users").Columns("a").Columns("b"), "CREATE INDEX IF NOT EXISTS users_ab_idx ON users (a, b);"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.index.Dry().Exec()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Query != tt.want {
+				t.Errorf("got  %s\nwant %s", metadata.Query, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	tests := []struct {
+		name  string
+		index *IndexInstance
+		err   string
+	}{
+		{"no name", c.NewIndex("").On("users").Columns("age"), "an index name is required"},
+		{"no table", c.NewIndex("users_age_idx").Columns("age"), "set it with On"},
+		{"no columns", c.NewIndex("users_age_idx").On("users"), "set them with Columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.index.Dry().Exec()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDropIndex(t *testing.T) {
+	c, fake := newTestClient(t)
+
+	if _, err := c.DropIndex("users_age_idx").IfExists().Concurrently().Exec(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"DROP INDEX CONCURRENTLY IF EXISTS users_age_idx;"}
+	if got := fake.queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestIndexTags(t *testing.T) {
+	got := createTable(t, func(c *Client) *TableInstance {
+		return c.NewTable("users").Fields(indexedUser{})
+	})
+	want := []string{
+		"CREATE TABLE IF NOT EXISTS users (email TEXT, tenant_id INT, name TEXT, age INT);",
+		"CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON users (email);",
+		"CREATE INDEX IF NOT EXISTS users_tenant_name_idx ON users (tenant_id, name);",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestIndexes(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(string) fakeResult {
+		return fakeResult{
+			columns: []string{"relname", "indisunique", "indisprimary", "columns", "where", "definition"},
+			rows: [][]driver.Value{
+				{"users_pkey", true, true, "id", "", "CREATE UNIQUE INDEX users_pkey ON public.users USING btree (id)"},
+				{"users_tenant_name_idx", false, false, "tenant_id,name", "(age > 17)", "CREATE INDEX ..."},
+			},
+		}
+	}
+
+	indexes, err := c.Indexes("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 2 {
+		t.Fatalf("got %v indexes, want 2", len(indexes))
+	}
+	if !indexes[0].Primary || !indexes[0].Unique {
+		t.Errorf("got %+v, want the primary key", indexes[0])
+	}
+	if got := indexes[1]; !reflect.DeepEqual(got.Columns, []string{"tenant_id", "name"}) || got.Where != "(age > 17)" || got.Table != "users" {
+		t.Errorf("got %+v", got)
+	}
+}
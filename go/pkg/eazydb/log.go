@@ -1,46 +1,85 @@
 package eazydb
 
 import (
+	"context"
 	"io"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 )
 
-func initLogger(logger *logrus.Logger, enabled bool) *logrus.Logger {
+// Logger receives everything eazydb logs. Fields are key value pairs, eg: "table", "users".
+// A *slog.Logger can be used as is, wrap a *logrus.Logger with LogrusLogger
+type Logger interface {
+	Debug(msg string, fields ...any)
+	Info(msg string, fields ...any)
+	Warn(msg string, fields ...any)
+	Error(msg string, fields ...any)
+}
+
+// initLogger returns the logger passed to the client, or a slog text logger on stderr
+// at the level set by EAZYDB_LOG_LEVEL, which defaults to info
+func initLogger(logger Logger, enabled bool) Logger {
 	if logger != nil {
 		return logger
 	}
 
-	levelstr := os.Getenv("EAZYDB_LOG_LEVEL")
-	var level logrus.Level = 4
-	switch levelstr {
-	case "panic":
-		level = 0
-	case "fatal":
-		level = 1
-	case "error":
-		level = 2
-	case "warning":
-		level = 4
-	case "info":
-		level = 5
-	case "debug":
-		level = 6
-	case "trace":
-		level = 7
+	var level slog.Level
+	switch strings.ToLower(os.Getenv("EAZYDB_LOG_LEVEL")) {
+	case "trace", "debug":
+		level = slog.LevelDebug
+	case "warn", "warning":
+		level = slog.LevelWarn
+	case "error", "fatal", "panic":
+		level = slog.LevelError
+	default:
+		level = slog.LevelInfo
 	}
 
-	log := &logrus.Logger{
-		Out:       os.Stderr,
-		Formatter: new(logrus.TextFormatter),
-		Hooks:     make(logrus.LevelHooks),
-		Level:     level,
+	var out io.Writer = os.Stderr
+	if !enabled {
+		out = io.Discard
 	}
+	return slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: level}))
+}
 
-	if !enabled {
-		log.Out = io.Discard
+type logrusLogger struct {
+	log *logrus.Logger
+}
+
+// LogrusLogger adapts a *logrus.Logger, fields are passed on as logrus fields
+func LogrusLogger(log *logrus.Logger) Logger {
+	return logrusLogger{log: log}
+}
+
+func (l logrusLogger) entry(fields []any) *logrus.Entry {
+	data := make(logrus.Fields, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok {
+			key = "!BADKEY"
+		}
+		data[key] = fields[i+1]
 	}
+	return l.log.WithFields(data)
+}
+
+func (l logrusLogger) Debug(msg string, fields ...any) { l.entry(fields).Debug(msg) }
+func (l logrusLogger) Info(msg string, fields ...any)  { l.entry(fields).Info(msg) }
+func (l logrusLogger) Warn(msg string, fields ...any)  { l.entry(fields).Warn(msg) }
+func (l logrusLogger) Error(msg string, fields ...any) { l.entry(fields).Error(msg) }
 
-	return log
+// Enabled lets query logs be skipped when logrus drops them
+func (l logrusLogger) Enabled(_ context.Context, level slog.Level) bool {
+	switch {
+	case level < slog.LevelInfo:
+		return l.log.IsLevelEnabled(logrus.DebugLevel)
+	case level < slog.LevelWarn:
+		return l.log.IsLevelEnabled(logrus.InfoLevel)
+	case level < slog.LevelError:
+		return l.log.IsLevelEnabled(logrus.WarnLevel)
+	}
+	return l.log.IsLevelEnabled(logrus.ErrorLevel)
 }
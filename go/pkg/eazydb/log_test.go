@@ -0,0 +1,96 @@
+package eazydb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]any
+}
+
+// recordLogger keeps every log so tests can check what was logged
+type recordLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordLogger) record(level string, msg string, fields []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := logEntry{level: level, msg: msg, fields: make(map[string]any)}
+	for i := 0; i+1 < len(fields); i += 2 {
+		entry.fields[fmt.Sprint(fields[i])] = fields[i+1]
+	}
+	l.entries = append(l.entries, entry)
+}
+
+func (l *recordLogger) Debug(msg string, fields ...any) { l.record("debug", msg, fields) }
+func (l *recordLogger) Info(msg string, fields ...any)  { l.record("info", msg, fields) }
+func (l *recordLogger) Warn(msg string, fields ...any)  { l.record("warn", msg, fields) }
+func (l *recordLogger) Error(msg string, fields ...any) { l.record("error", msg, fields) }
+
+func TestInitLogger(t *testing.T) {
+	rec := &recordLogger{}
+	if got := initLogger(rec, false); got != rec {
+		t.Errorf("a passed logger should be used as is, got %T", got)
+	}
+
+	t.Setenv("EAZYDB_LOG_LEVEL", "warn")
+	log, ok := initLogger(nil, true).(*slog.Logger)
+	if !ok {
+		t.Fatalf("got %T, want a *slog.Logger", log)
+	}
+	if log.Enabled(context.Background(), slog.LevelInfo) || !log.Enabled(context.Background(), slog.LevelWarn) {
+		t.Errorf("EAZYDB_LOG_LEVEL=warn was not applied")
+	}
+}
+
+func TestLogrusLogger(t *testing.T) {
+	var out bytes.Buffer
+	lr := logrus.New()
+	lr.SetOutput(&out)
+	lr.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+	lr.SetLevel(logrus.InfoLevel)
+	log := LogrusLogger(lr)
+
+	log.Info("connected", "attempts", 3, 42, "odd key")
+	if got := out.String(); !strings.Contains(got, `msg=connected`) || !strings.Contains(got, "attempts=3") || !strings.Contains(got, `!BADKEY="odd key"`) {
+		t.Errorf("got %q", got)
+	}
+
+	out.Reset()
+	log.Debug("dropped")
+	if out.Len() != 0 {
+		t.Errorf("a debug log was written at info level: %q", out.String())
+	}
+
+	leveled := log.(interface {
+		Enabled(context.Context, slog.Level) bool
+	})
+	if leveled.Enabled(context.Background(), slog.LevelDebug) || !leveled.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("Enabled does not follow the logrus level")
+	}
+}
+
+func TestQueriesSkippedWhenDebugIsOff(t *testing.T) {
+	var out bytes.Buffer
+	c, _ := newTestClient(t)
+	c.log = newQueryLogger(slog.New(slog.NewTextHandler(&out, nil)), QueryLog{})
+
+	if _, err := c.Table("users").Delete().Where(*Int("id").Equals(1)).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("a query was logged at info level: %q", out.String())
+	}
+}
@@ -0,0 +1,342 @@
+package eazydb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// Model is a table that knows its primary key, so rows can be found, saved
+// and deleted by key without writing the conditions by hand
+type Model struct {
+	client *Client
+	name   string
+	keys   []string
+}
+
+// keyRegistry remembers the primary keys of tables created with NewTable
+type keyRegistry struct {
+	mu   sync.RWMutex
+	keys map[string][]string
+}
+
+func newKeyRegistry() *keyRegistry {
+	return &keyRegistry{keys: make(map[string][]string)}
+}
+
+func (r *keyRegistry) set(table string, keys []string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[table] = keys
+}
+
+func (r *keyRegistry) remove(table string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, table)
+}
+
+func (r *keyRegistry) rename(table string, newName string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if keys, ok := r.keys[table]; ok {
+		r.keys[newName] = keys
+		delete(r.keys, table)
+	}
+}
+
+func (r *keyRegistry) get(table string) []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys[table]
+}
+
+// Model returns a table that knows its primary key. When no keys are passed they are taken
+// from NewTable(...).Key if the table was created by this client, otherwise from the database
+func (c *Client) Model(name string, keys ...string) *Model {
+	return &Model{
+		client: c,
+		name:   name,
+		keys:   keys,
+	}
+}
+
+// Keys returns the primary key columns of the table in order
+func (m *Model) Keys(ctx context.Context) ([]string, error) {
+	if len(m.keys) > 0 {
+		return m.keys, nil
+	}
+	if keys := m.client.keys.get(m.name); len(keys) > 0 {
+		return keys, nil
+	}
+
+	keys, err := m.client.primaryKeys(ctx, m.name)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up the primary key of %s: %v", m.name, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key, pass the key columns to Model", m.name)
+	}
+	m.client.keys.set(m.name, keys)
+	return keys, nil
+}
+
+// Find reads the row with the given key into dest, which must be a pointer to a struct.
+// Composite keys are passed in the same order as the key columns. ErrNoRows is returned if there is no such row
+//
+//	var u User
+//	err := c.Model("users").Find(ctx, &u, 42)
+func (m *Model) Find(ctx context.Context, dest interface{}, key ...interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Find needs a pointer to a struct, got %T", dest)
+	}
+
+	cond, err := m.keyCondition(ctx, [][]interface{}{key})
+	if err != nil {
+		return err
+	}
+
+	found := reflect.New(reflect.SliceOf(v.Elem().Type()))
+	metadata, err := m.client.Table(m.name).Get(dest).Where(cond).MaxRows(1).ExecContext(ctx, found.Interface())
+	if err != nil {
+		return err
+	}
+	if found.Elem().Len() == 0 {
+		return noRowsError(m.name, dbtypes.SELECT, metadata.Query, fmt.Sprintf("no row with key %v", key))
+	}
+	v.Elem().Set(found.Elem().Index(0))
+	return nil
+}
+
+// Save inserts the row if its key is not set yet, reading the generated key back into it.
+// Otherwise the row is inserted or, if the key already exists, every other column is updated
+func (m *Model) Save(ctx context.Context, row interface{}) (*Metadata, error) {
+	keys, err := m.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := constructFields(row, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	keySet := true
+	for _, f := range all {
+		if isKey(keys, f.Name) {
+			if f.Val == nil || reflect.ValueOf(f.Val).IsZero() {
+				keySet = false
+			}
+			continue
+		}
+		columns = append(columns, f.Name)
+	}
+	for _, key := range keys {
+		if !hasField(all, key) {
+			return nil, fmt.Errorf("key %v is not a field of %T", key, row)
+		}
+	}
+
+	if !keySet {
+		return m.client.Table(m.name).Add(row).Returning(keys...).ExecContext(ctx)
+	}
+
+	q := m.client.Table(m.name).Add(row).Columns(append(append([]string{}, keys...), columns...)...).OnConflict(keys...)
+	updates, err := withoutCreatedAt(row, columns)
+	if err != nil {
+		return nil, err
+	}
+	if len(updates) == 0 {
+		q = q.DoNothing()
+	} else {
+		q = q.DoUpdate(updates...)
+	}
+	return q.ExecContext(ctx)
+}
+
+// DeleteByKey deletes the rows with the given keys. Each key is either the key value,
+// a struct with the key fields set, or for composite keys a slice of values in key order
+func (m *Model) DeleteByKey(ctx context.Context, keys ...interface{}) (*Metadata, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("DeleteByKey requires at least one key")
+	}
+
+	cols, err := m.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([][]interface{}, len(keys))
+	for i, key := range keys {
+		vals[i], err = keyValues(cols, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cond, err := m.keyCondition(ctx, vals)
+	if err != nil {
+		return nil, err
+	}
+	return m.client.Table(m.name).Delete().Where(cond).ExecContext(ctx)
+}
+
+// Produces a condition like below
+//
+//	id IN ($1, $2)
+//	(tenant_id, id) IN (($1, $2), ($3, $4))
+func (m *Model) keyCondition(ctx context.Context, vals [][]interface{}) (Condition, error) {
+	keys, err := m.Keys(ctx)
+	if err != nil {
+		return Condition{}, err
+	}
+
+	names := strings.Join(keys, ", ")
+	if len(keys) > 1 {
+		names = "(" + names + ")"
+	}
+
+	cond := Condition{}
+	cond.writeSQL(names + " IN (")
+	for i, val := range vals {
+		if len(val) != len(keys) {
+			return Condition{}, fmt.Errorf("%s has %v key columns %v but %v values were passed", m.name, len(keys), keys, len(val))
+		}
+		if i > 0 {
+			cond.writeSQL(", ")
+		}
+		if len(keys) > 1 {
+			cond.writeSQL("(")
+		}
+		for j, v := range val {
+			if j > 0 {
+				cond.writeSQL(", ")
+			}
+			cond.writeValue(keys[j], v)
+		}
+		if len(keys) > 1 {
+			cond.writeSQL(")")
+		}
+	}
+	cond.writeSQL(")")
+	return cond, nil
+}
+
+// keyValues returns the key values of a struct, slice or single value in key order
+func keyValues(keys []string, key interface{}) ([]interface{}, error) {
+	v := reflect.ValueOf(key)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if _, ok := key.(Expression); ok {
+			break
+		}
+		fields, err := pickColumns(key, keys)
+		if err != nil {
+			return nil, err
+		}
+		vals := make([]interface{}, len(fields))
+		for i, f := range fields {
+			vals[i] = f.Val
+		}
+		return vals, nil
+	case reflect.Slice:
+		if _, ok := key.([]byte); ok {
+			break
+		}
+		vals := make([]interface{}, v.Len())
+		for i := range vals {
+			vals[i] = v.Index(i).Interface()
+		}
+		return vals, nil
+	}
+	return []interface{}{key}, nil
+}
+
+// withoutCreatedAt drops the createdat column so an existing row keeps the time it was created
+func withoutCreatedAt(row interface{}, columns []string) ([]string, error) {
+	t := reflect.TypeOf(row)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	created, _, err := timestampFields(t)
+	if err != nil || created == nil {
+		return columns, err
+	}
+
+	kept := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col != created.Tag.Get("json") {
+			kept = append(kept, col)
+		}
+	}
+	return kept, nil
+}
+
+func isKey(keys []string, name string) bool {
+	for _, key := range keys {
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasField(fields []field, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// primaryKeys looks up the primary key columns of a table in order
+func (c *Client) primaryKeys(ctx context.Context, table string) ([]string, error) {
+	var keys []string
+	query := `
+		SELECT kcu.column_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
+		WHERE tc.table_name = $1 AND tc.table_schema = 'public' AND tc.constraint_type = 'PRIMARY KEY'
+		ORDER BY kcu.ordinal_position;
+	`
+
+	rows, err := c.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		keys = append(keys, column)
+	}
+
+	return keys, rows.Err()
+}
@@ -0,0 +1,122 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type modelUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type membership struct {
+	TenantID int    `json:"tenant_id"`
+	UserID   int    `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+func TestModelFind(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(string) fakeResult {
+		return fakeResult{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(42), "Mat"}}}
+	}
+
+	var u modelUser
+	if err := c.Model("users", "id").Find(context.Background(), &u, 42); err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 42 || u.Name != "Mat" {
+		t.Errorf("got %+v", u)
+	}
+	if got := fake.queries(); len(got) != 1 || !strings.HasSuffix(got[0], "FROM users WHERE id IN ($1) LIMIT 1 [42]") {
+		t.Errorf("got %q", got)
+	}
+
+	fake.respond = func(string) fakeResult { return fakeResult{columns: []string{"id", "name"}} }
+	if err := c.Model("users", "id").Find(context.Background(), &u, 7); !errors.Is(err, ErrNoRows) {
+		t.Errorf("got error %v, want ErrNoRows", err)
+	}
+	if err := c.Model("users", "id").Find(context.Background(), u, 7); err == nil || !strings.Contains(err.Error(), "pointer to a struct") {
+		t.Errorf("got error %v for a struct passed by value", err)
+	}
+}
+
+func TestModelSave(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(query string) fakeResult {
+		if strings.Contains(query, "RETURNING id") {
+			return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(5)}}}
+		}
+		return defaultResult(query)
+	}
+	model := c.Model("users", "id")
+
+	created := modelUser{Name: "Mat"}
+	if _, err := model.Save(context.Background(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 5 {
+		t.Errorf("got id %v, want the generated key read back", created.ID)
+	}
+
+	if _, err := model.Save(context.Background(), &modelUser{ID: 5, Name: "Ann"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"INSERT INTO users (name) VALUES ($1) RETURNING id; [Mat]",
+		"INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name RETURNING (xmax = 0) AS eazydb_inserted; [5 Ann]",
+	}
+	if got := fake.queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestModelDeleteByCompositeKey(t *testing.T) {
+	c, fake := newTestClient(t)
+	c.keys.set("memberships", []string{"tenant_id", "user_id"})
+
+	_, err := c.Model("memberships").DeleteByKey(context.Background(), []int{1, 2}, membership{TenantID: 1, UserID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "DELETE FROM memberships  WHERE (tenant_id, user_id) IN (($1, $2), ($3, $4)) [1 2 1 3]"
+	if got := fake.queries(); len(got) != 1 || got[0] != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	_, err = c.Model("memberships").DeleteByKey(context.Background(), 1)
+	if err == nil || !strings.Contains(err.Error(), "has 2 key columns") {
+		t.Errorf("got error %v for a partial key", err)
+	}
+}
+
+func TestModelKeysFromDatabase(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(query string) fakeResult {
+		if strings.Contains(query, "information_schema") {
+			return fakeResult{columns: []string{"column_name"}, rows: [][]driver.Value{{"tenant_id"}, {"user_id"}}}
+		}
+		return defaultResult(query)
+	}
+
+	keys, err := c.Model("memberships").Keys(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"tenant_id", "user_id"}) {
+		t.Errorf("got keys %v", keys)
+	}
+	if got := c.keys.get("memberships"); !reflect.DeepEqual(got, keys) {
+		t.Errorf("keys were not remembered, got %v", got)
+	}
+
+	fake.respond = func(string) fakeResult { return fakeResult{columns: []string{"column_name"}} }
+	if _, err := c.Model("logs").Keys(context.Background()); err == nil || !strings.Contains(err.Error(), "has no primary key") {
+		t.Errorf("got error %v for a table without a key", err)
+	}
+}
@@ -0,0 +1,136 @@
+package eazydb
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// Parallel splits the rows passed to Add across the given number of pooled connections.
+// Each batch is written in its own transaction. Batches that fail are rolled back and
+// reported in Metadata.Batches with their error so they can be retried.
+func (q *Query) Parallel(workers int) *Query {
+	if q.op != dbtypes.INSERT {
+		q.err = fmt.Errorf("Parallel can only be used with Add, table operation is %v", q.op)
+		return q
+	}
+	if workers <= 0 {
+		q.err = fmt.Errorf("number of workers must be greater than 0, got %v", workers)
+		return q
+	}
+	q.workers = workers
+	return q
+}
+
+func (q *Query) handleParallel(queries []statement, chunks []chunk, target interface{}) (*Metadata, error) {
+	if q.tx != nil {
+		return nil, fmt.Errorf("Parallel cannot be used inside a transaction")
+	}
+	if target != nil && len(q.returning) > 0 {
+		return nil, fmt.Errorf("Parallel writes returned values back into the rows passed to Add, a target cannot be passed to Exec")
+	}
+	if max := q.db.Stats().MaxOpenConnections; max > 0 && max < q.workers {
+		q.log.Warn("more workers requested than the pool allows open connections", "table", q.name, "workers", q.workers, "max_open_conns", max)
+	}
+
+	results := make([]*Metadata, len(chunks))
+	errs := make([]error, len(chunks))
+	jobs := make(chan int)
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < q.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = q.runChunk(queries, chunks[i])
+			}
+		}()
+	}
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var metadata *Metadata = &Metadata{}
+	metadata.Query, metadata.Args = joinStatements(queries)
+
+	var failed []string
+	var failedErrs []error
+	for i, c := range chunks {
+		if errs[i] != nil {
+			failedErrs = append(failedErrs, errs[i])
+			q.log.Error("batch failed and was rolled back", "table", q.name, "batch", i+1, "batches", len(chunks), "error", errs[i])
+			failed = append(failed, fmt.Sprintf("rows %v-%v: %v", c.start, c.end, errs[i]))
+			metadata.Batches = append(metadata.Batches, Batch{
+				Index: c.index,
+				Start: c.start,
+				End:   c.end,
+				Rows:  c.end - c.start,
+				Err:   errs[i],
+			})
+			continue
+		}
+		metadata.addBatch(c, results[i])
+	}
+	// batches overlap, so report the wall time rather than the sum
+	metadata.Duration = time.Since(now)
+
+	if len(failed) > 0 {
+		return metadata, &batchErrors{
+			msg:  fmt.Sprintf("%v of %v batches failed: %s", len(failed), len(chunks), strings.Join(failed, "; ")),
+			errs: failedErrs,
+		}
+	}
+	return metadata, nil
+}
+
+// batchErrors keeps the errors of the failed batches so they can be matched with errors.Is
+type batchErrors struct {
+	msg  string
+	errs []error
+}
+
+func (e *batchErrors) Error() string {
+	return e.msg
+}
+
+func (e *batchErrors) Unwrap() []error {
+	return e.errs
+}
+
+// runChunk writes a single batch in its own transaction
+func (q *Query) runChunk(queries []statement, c chunk) (*Metadata, error) {
+	tx, err := q.db.BeginTx(q.ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	worker := *q
+	worker.tx = tx
+	worker.fields = c.rows
+	worker.batchSize = c.end - c.start
+	worker.workers = 0
+
+	// copy builds its statement per batch, so there is only one query to pass along
+	query := queries[0]
+	if !q.copy {
+		query = queries[c.index]
+	}
+
+	metadata, err := worker.handleInsert([]statement{query}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	q.log.Debug("batch added rows", "table", q.name, "batch", c.index+1, "rows", metadata.RowsAffected)
+	return metadata, nil
+}
@@ -0,0 +1,71 @@
+package eazydb
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParallelGivesEveryWorkerABatch(t *testing.T) {
+	c, fake := newTestClient(t)
+	users := []batchUser{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+
+	metadata, err := c.Table("users").Add(users).Parallel(3).Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metadata.Batches) != 3 || metadata.RowsAffected != 5 {
+		t.Fatalf("got %v rows affected in %v batches, want 5 in 3", metadata.RowsAffected, len(metadata.Batches))
+	}
+
+	got := fake.queries()
+	sort.Strings(got)
+	want := []string{
+		"INSERT INTO users (name) VALUES ($1), ($2); [a b]",
+		"INSERT INTO users (name) VALUES ($1), ($2); [c d]",
+		"INSERT INTO users (name) VALUES ($1); [e]",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	if commits := len(fake.statements()) - len(got); commits != 6 {
+		t.Errorf("got %v BEGIN and COMMIT statements, want one of each per batch", commits)
+	}
+}
+
+func TestParallelReportsFailedBatches(t *testing.T) {
+	c, fake := newTestClient(t)
+	errFull := errors.New("disk full")
+	fake.respond = func(query string) fakeResult {
+		if strings.HasSuffix(query, "[b]") {
+			return fakeResult{err: errFull}
+		}
+		return defaultResult(query)
+	}
+
+	users := []batchUser{{Name: "a"}, {Name: "b"}}
+	metadata, err := c.Table("users").Add(users).Parallel(2).Exec()
+	if !errors.Is(err, errFull) {
+		t.Fatalf("got error %v, want it to wrap %v", err, errFull)
+	}
+	if metadata.RowsAffected != 1 || len(metadata.Batches) != 2 {
+		t.Fatalf("got %v rows affected in %v batches, want 1 in 2", metadata.RowsAffected, len(metadata.Batches))
+	}
+	for _, b := range metadata.Batches {
+		if (b.Start == 1) != (b.Err != nil) {
+			t.Errorf("got batch %+v, only rows 1-2 should have failed", b)
+		}
+	}
+}
+
+func TestParallelErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	if _, err := c.Table("users").Add([]batchUser{{Name: "a"}}).Parallel(0).Dry().Exec(); err == nil || !strings.Contains(err.Error(), "greater than 0") {
+		t.Errorf("got error %v for no workers", err)
+	}
+	if _, err := c.Table("users").Get(batchUser{}).Parallel(2).Dry().Exec(); err == nil || !strings.Contains(err.Error(), "only be used with Add") {
+		t.Errorf("got error %v for Parallel on Get", err)
+	}
+}
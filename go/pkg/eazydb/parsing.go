@@ -2,20 +2,56 @@ package eazydb
 
 import (
 	"fmt"
+	"math"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
 )
 
 func validateOptions(opt *ClientOptions) error {
-	if opt.User == "" {
+	if opt.Type != POSTGRES {
+		return fmt.Errorf("database type %v is not supported, only %v is", opt.Type, POSTGRES)
+	}
+	if scheme, _, ok := strings.Cut(opt.DSN, "://"); ok && scheme != "postgres" && scheme != "postgresql" {
+		return fmt.Errorf("DSN scheme %s is not supported, use postgres://", scheme)
+	}
+	if opt.MaxOpenConns < 0 || opt.MaxIdleConns < 0 {
+		return fmt.Errorf("MaxOpenConns and MaxIdleConns cannot be negative")
+	}
+	if opt.Retry.MaxAttempts < 0 {
+		return fmt.Errorf("Retry.MaxAttempts cannot be negative, got %v", opt.Retry.MaxAttempts)
+	}
+	if opt.ConnectRetry.MaxAttempts < 0 || opt.ConnectRetry.Deadline < 0 {
+		return fmt.Errorf("ConnectRetry.MaxAttempts and ConnectRetry.Deadline cannot be negative")
+	}
+	if opt.ReplicaRouting != "" && opt.ReplicaRouting != RoundRobin && opt.ReplicaRouting != LeastLatency {
+		return fmt.Errorf("ReplicaRouting must be %s or %s, got %s", RoundRobin, LeastLatency, opt.ReplicaRouting)
+	}
+	if opt.ReplicaHealthCheck < 0 {
+		return fmt.Errorf("ReplicaHealthCheck cannot be negative, got %v", opt.ReplicaHealthCheck)
+	}
+	if err := opt.QueryLog.validate(); err != nil {
+		return err
+	}
+	if opt.MaxRowsAffected < 0 {
+		return fmt.Errorf("MaxRowsAffected cannot be negative, got %v", opt.MaxRowsAffected)
+	}
+	if opt.DSN != "" {
+		return nil
+	}
+
+	if opt.User == "" && opt.Credentials == nil {
 		return fmt.Errorf("User is not set, either pass as a client option or set DB_USER")
 	}
-	if opt.Password == "" {
-		return fmt.Errorf("Password is not set, either pass as a client option or set DB_PASS")
+	if opt.Password == "" && opt.Credentials == nil {
+		return fmt.Errorf("Password is not set, either pass as a client option or set DB_PASS or DB_PASS_FILE")
 	}
 	if opt.Host == "" {
 		return fmt.Errorf("Host is not set, either pass as a client option or set DB_HOST")
 	}
-	if opt.Port == "" {
+	// a unix socket directory defaults to the socket for port 5432
+	if opt.Port == "" && !isSocket(opt.Host) {
 		return fmt.Errorf("Port is not set, either pass as a client option or set DB_PORT")
 	}
 	if opt.Name == "" {
@@ -52,9 +88,149 @@ func parseOptions(opts ...ClientOptions) (*ClientOptions, error) {
 		}
 	}
 
+	if opt.DSN == "" && opt.Host == "" {
+		opt.DSN = os.Getenv("DATABASE_URL")
+	}
+	if opt.Type == "" {
+		opt.Type = POSTGRES
+	}
+	// mounted secrets are read on every new connection so rotations are picked up
+	if path := os.Getenv("DB_PASS_FILE"); opt.Credentials == nil && opt.Password == "" && path != "" {
+		opt.Credentials = FileCredentials(opt.User, path)
+	}
+
 	if err := validateOptions(&opt); err != nil {
 		return nil, err
 	}
 
 	return &opt, nil
 }
+
+func isSocket(host string) bool {
+	return strings.HasPrefix(host, "/")
+}
+
+// connectionParams are the options set alongside the host and credentials
+func (opt *ClientOptions) connectionParams() map[string]string {
+	params := map[string]string{
+		"sslmode":          opt.SSLMode,
+		"sslcert":          opt.SSLCert,
+		"sslkey":           opt.SSLKey,
+		"sslrootcert":      opt.SSLRootCert,
+		"application_name": opt.ApplicationName,
+		"search_path":      opt.SearchPath,
+	}
+	if opt.ConnectTimeout > 0 {
+		// only whole seconds are supported
+		params["connect_timeout"] = fmt.Sprint(int(math.Ceil(opt.ConnectTimeout.Seconds())))
+	}
+	for k, v := range params {
+		if v == "" {
+			delete(params, k)
+		}
+	}
+	return params
+}
+
+// dataSourceName builds the connection string passed to the driver. Options set in
+// ClientOptions take precedence over the same options in DSN
+func (opt *ClientOptions) dataSourceName() (string, error) {
+	params := opt.connectionParams()
+
+	if strings.Contains(opt.DSN, "://") {
+		u, err := url.Parse(opt.DSN)
+		if err != nil {
+			return "", fmt.Errorf("could not parse DSN: %v", err)
+		}
+		query := u.Query()
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	dsn := opt.DSN
+	if dsn == "" {
+		if _, ok := params["sslmode"]; !ok {
+			params["sslmode"] = "disable"
+		}
+		port := opt.Port
+		if port == "" {
+			port = "5432"
+		}
+		dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s",
+			quoteParam(opt.Host), quoteParam(port), quoteParam(opt.User), quoteParam(opt.Password), quoteParam(opt.Name))
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	// later values win, so these override any set in DSN
+	for _, k := range keys {
+		dsn += fmt.Sprintf(" %s=%s", k, quoteParam(params[k]))
+	}
+	return dsn, nil
+}
+
+// quoteParam quotes a key=value connection string value if it is empty or has spaces or quotes
+func quoteParam(val string) string {
+	if val != "" && !strings.ContainsAny(val, ` '\`) {
+		return val
+	}
+	val = strings.ReplaceAll(val, `\`, `\\`)
+	val = strings.ReplaceAll(val, `'`, `\'`)
+	return "'" + val + "'"
+}
+
+// parseParams parses a key=value connection string. Values can be single quoted, with
+// backslash escaping quotes and backslashes, and spaces are allowed around the =
+func parseParams(dsn string) (map[string]string, error) {
+	params := make(map[string]string)
+	s := strings.TrimLeft(dsn, " \t\n\r")
+	for s != "" {
+		eq := strings.IndexRune(s, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("could not parse DSN: missing = after %q", s)
+		}
+		key := strings.TrimRight(s[:eq], " \t\n\r")
+		if key == "" || strings.ContainsAny(key, " \t\n\r") {
+			return nil, fmt.Errorf("could not parse DSN: invalid key %q", key)
+		}
+		s = strings.TrimLeft(s[eq+1:], " \t\n\r")
+
+		var val strings.Builder
+		quoted := strings.HasPrefix(s, "'")
+		if quoted {
+			s = s[1:]
+		}
+		closed := !quoted
+		i := 0
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) {
+				i++
+				val.WriteByte(s[i])
+				continue
+			}
+			if quoted && c == '\'' {
+				closed = true
+				i++
+				break
+			}
+			if !quoted && strings.ContainsRune(" \t\n\r", rune(c)) {
+				break
+			}
+			val.WriteByte(c)
+		}
+		if !closed {
+			return nil, fmt.Errorf("could not parse DSN: unterminated quote in %s", key)
+		}
+		// later values win
+		params[key] = val.String()
+		s = strings.TrimLeft(s[i:], " \t\n\r")
+	}
+	return params, nil
+}
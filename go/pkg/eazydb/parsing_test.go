@@ -0,0 +1,105 @@
+package eazydb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv stops the connection variables of the machine running the tests leaking in
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{"DB_USER", "DB_PASS", "DB_PASS_FILE", "DB_HOST", "DB_PORT", "DB_NAME", "DB_TYPE", "DATABASE_URL"} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestDataSourceName(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  ClientOptions
+		want string
+	}{
+		{
+			name: "fields default to sslmode disable",
+			opt:  ClientOptions{User: "app", Password: "p w", Host: "db", Port: "5433", Name: "app"},
+			want: "host=db port=5433 user=app password='p w' dbname=app sslmode=disable",
+		},
+		{
+			name: "socket host defaults the port",
+			opt:  ClientOptions{User: "app", Password: "pw", Host: "/var/run/postgresql", Name: "app", ConnectTimeout: 1500 * time.Millisecond},
+			want: "host=/var/run/postgresql port=5432 user=app password=pw dbname=app connect_timeout=2 sslmode=disable",
+		},
+		{
+			name: "url keeps its own sslmode",
+			opt:  ClientOptions{DSN: "postgres://app:pw@db/app?sslmode=require", ApplicationName: "billing"},
+			want: "postgres://app:pw@db/app?application_name=billing&sslmode=require",
+		},
+		{
+			name: "url without sslmode is left to the driver default",
+			opt:  ClientOptions{DSN: "postgres://app:pw@db/app"},
+			want: "postgres://app:pw@db/app",
+		},
+		{
+			name: "options override key value dsn",
+			opt:  ClientOptions{DSN: "host=db sslmode=disable", SSLMode: "verify-full", SearchPath: "app,public"},
+			want: "host=db sslmode=disable search_path=app,public sslmode=verify-full",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opt.dataSourceName()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionsReadsDatabaseURL(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL", "postgres://app:pw@db/app")
+
+	opt, err := parseOptions(ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opt.DSN != "postgres://app:pw@db/app" || opt.Type != POSTGRES {
+		t.Errorf("got DSN %q and type %q", opt.DSN, opt.Type)
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	clearEnv(t)
+	valid := ClientOptions{User: "app", Password: "pw", Host: "db", Port: "5432", Name: "app"}
+
+	tests := []struct {
+		name   string
+		change func(opt *ClientOptions)
+		err    string
+	}{
+		{"valid", func(opt *ClientOptions) {}, ""},
+		{"socket without port", func(opt *ClientOptions) { opt.Host, opt.Port = "/tmp", "" }, ""},
+		{"missing port", func(opt *ClientOptions) { opt.Port = "" }, "Port is not set"},
+		{"missing password", func(opt *ClientOptions) { opt.Password = "" }, "Password is not set"},
+		{"unsupported type", func(opt *ClientOptions) { opt.Type = "mysql" }, "database type mysql is not supported"},
+		{"unsupported scheme", func(opt *ClientOptions) { opt.DSN = "mysql://app@db/app" }, "DSN scheme mysql is not supported"},
+		{"negative pool", func(opt *ClientOptions) { opt.MaxOpenConns = -1 }, "cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := valid
+			tt.change(&opt)
+			_, err := parseOptions(opt)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package eazydb
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -10,11 +11,18 @@ import (
 	"time"
 
 	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
-	"github.com/sirupsen/logrus"
 )
 
+// executor is satisfied by both *sql.DB and *sql.Tx
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
 type Query struct {
+	ctx               context.Context
 	db                *sql.DB
+	tx                *sql.Tx
 	name              string
 	fields            interface{}
 	conditions        []Condition
@@ -23,7 +31,25 @@ type Query struct {
 	dryrun            bool
 	errIfNoneReturned bool
 	err               error
-	log               *logrus.Logger
+	log               *queryLogger
+	dbType            DB_TYPE
+	conflict          *conflictClause
+	returning         []string
+	batchSize         int
+	copy              bool
+	workers           int
+	preloads          []string
+	columns           []string
+	sets              []field
+	unsafe            bool
+	maxAffected       int
+	softDeletes       *tableSet
+	scope             deletedScope
+	hardDelete        bool
+	clock             func() time.Time
+	retry             RetryPolicy
+	replicas          *replicaSet
+	primary           bool
 }
 
 func (c *Client) Table(name string) *Query {
@@ -32,14 +58,42 @@ func (c *Client) Table(name string) *Query {
 		err = errors.New("a table name is required")
 	}
 	return &Query{
-		db:   c.DB,
-		name: name,
-		err:  err,
-		log:  c.log,
+		db:          c.DB,
+		name:        name,
+		err:         err,
+		log:         c.log,
+		dbType:      c.dbType,
+		unsafe:      c.unsafe,
+		maxAffected: c.maxAffected,
+		softDeletes: c.softDeletes,
+		clock:       c.clock,
+		retry:       c.retry,
+		replicas:    c.replicas,
 	}
 
 }
 
+// conn returns the transaction the query is bound to, a replica for reads, or the primary
+func (q *Query) conn() executor {
+	if q.tx != nil {
+		return q.tx
+	}
+	if q.op == dbtypes.SELECT && !q.primary {
+		if replica := q.replicas.pick(); replica != nil {
+			return replica
+		}
+	}
+	return q.db
+}
+
+// Primary sends a read to the primary instead of a replica, eg: to read a row just written
+func (q *Query) Primary() *Query {
+	q.primary = true
+	return q
+}
+
+// ErrIfNoneReturned returns ErrNoRows when Get or Returning reads no rows,
+// or when Update or Delete doesn't change any
 func (q *Query) ErrIfNoneReturned() *Query {
 	q.errIfNoneReturned = true
 	return q
@@ -64,20 +118,34 @@ func (q *Query) Get(fields interface{}) *Query {
 	return q
 }
 
-func (q *Query) Delete() *Query {
+// Delete removes the rows matching the conditions. A row can be passed for its BeforeDelete hook
+func (q *Query) Delete(row ...interface{}) *Query {
 	if q.op != "" {
 		q.err = fmt.Errorf("table operation already set to %v and so cannot be set to delete", q.op)
 	}
+	if len(row) > 1 {
+		q.err = fmt.Errorf("Delete takes a single row, got %v", len(row))
+	}
 	q.op = dbtypes.DELETE
+	if len(row) == 1 {
+		q.fields = row[0]
+	}
 	return q
 }
 
-func (q *Query) Update(fields interface{}) *Query {
+// Update sets the non zero fields of the struct. The struct can be left out when
+// only using Set, Inc or SetNow
+func (q *Query) Update(fields ...interface{}) *Query {
 	if q.op != "" {
 		q.err = fmt.Errorf("table operation already set to %v and so cannot be set to update", q.op)
 	}
+	if len(fields) > 1 {
+		q.err = fmt.Errorf("Update takes a single struct, got %v", len(fields))
+	}
 	q.op = dbtypes.UPDATE
-	q.fields = fields
+	if len(fields) == 1 {
+		q.fields = fields[0]
+	}
 	return q
 }
 
@@ -92,6 +160,12 @@ func (q *Query) Add(fields interface{}) *Query {
 }
 
 func (q *Query) Exec(obj ...interface{}) (*Metadata, error) {
+	return q.ExecContext(context.Background(), obj...)
+}
+
+// ExecContext runs the query, cancelling it if ctx is done
+func (q *Query) ExecContext(ctx context.Context, obj ...interface{}) (*Metadata, error) {
+	q.ctx = ctx
 	if q.name == "" {
 		return nil, errors.New("table name is required")
 	}
@@ -101,72 +175,186 @@ func (q *Query) Exec(obj ...interface{}) (*Metadata, error) {
 	if q.op == "" {
 		return nil, errors.New("a table operation must be set. eg: Table(users).Get()")
 	}
+	if err := q.checkBounded(); err != nil {
+		return nil, err
+	}
+	// hooks can have side effects, so a dry run skips them. It still shows the timestamps
+	// that would be written, without setting them on the caller's rows
+	if q.dryrun {
+		q.fields = cloneRows(q.fields)
+	} else if err := q.beforeHooks(); err != nil {
+		return nil, err
+	}
+	if q.op == dbtypes.INSERT {
+		if err := q.stampInsert(); err != nil {
+			return nil, err
+		}
+	}
+	if q.op == dbtypes.UPDATE {
+		if err := q.stampUpdate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := q.validate(); err != nil {
+		return nil, err
+	}
 
 	var metadata *Metadata = &Metadata{}
 	var err error
 
-	metadata.Query, err = q.constructQuery()
+	queries, err := q.constructQueries()
 	if err != nil {
 		return nil, err
 	}
+	metadata.Query, metadata.Args = joinStatements(queries)
 
 	if q.dryrun {
 		return metadata, nil
 	}
 
-	if q.op == dbtypes.INSERT || q.op == dbtypes.DELETE || q.op == dbtypes.UPDATE {
-		return q.handleExec(metadata.Query)
+	var target interface{}
+	if len(obj) > 0 {
+		target = obj[0]
+	}
+	if q.op == dbtypes.SELECT && target == nil {
+		return nil, errors.New("Get needs a pointer to read the rows into, pass it to Exec")
 	}
 
-	return q.handleSelect(metadata.Query, &obj[0])
+	policy := q.retry
+	// only reads outside a transaction are safe to run again
+	if q.op != dbtypes.SELECT || q.tx != nil {
+		policy = RetryPolicy{}
+	}
+	attempts, err := retry(q.ctx, policy, q.log, fmt.Sprintf("query on %s", q.name), func(int) error {
+		var err error
+		// an AfterInsert error has to be able to roll back the rows it was called on
+		if q.tx == nil && q.workers <= 1 && q.hasAfterInsert() {
+			metadata, err = q.runInTransaction(queries, target)
+		} else {
+			metadata, err = q.run(queries, target)
+		}
+		return err
+	})
+	if metadata != nil {
+		metadata.Attempts = attempts
+	}
+	return metadata, err
+}
 
+func (q *Query) run(queries []statement, target interface{}) (*Metadata, error) {
+	var metadata *Metadata
+	var err error
+
+	// only inserts are split into several statements
+	query := queries[0]
+
+	switch {
+	case q.op == dbtypes.INSERT:
+		metadata, err = q.handleInsert(queries, target)
+	case q.guarded():
+		metadata, err = q.handleGuarded(query, target)
+	case q.hasReturning():
+		metadata, err = q.handleReturning(query, target)
+	case q.op == dbtypes.DELETE || q.op == dbtypes.UPDATE:
+		metadata, err = q.handleExec(query)
+	default:
+		metadata, err = q.handleSelect(query, &target)
+		if err == nil && len(q.preloads) > 0 {
+			err = q.loadRelations(target)
+		}
+	}
+	if err != nil {
+		sql, _ := joinStatements(queries)
+		return metadata, q.queryError(err, sql)
+	}
+	if err := q.checkNoneReturned(metadata); err != nil {
+		return metadata, err
+	}
+	return metadata, q.afterHooks(target)
 }
 
-func (q *Query) handleSelect(query string, obj interface{}) (*Metadata, error) {
+// runInTransaction runs the query and its hooks in a transaction that is rolled back if either fails
+func (q *Query) runInTransaction(queries []statement, target interface{}) (*Metadata, error) {
+	tx, err := q.db.BeginTx(q.ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	worker := *q
+	worker.tx = tx
+	metadata, err := worker.run(queries, target)
+	if err != nil {
+		return metadata, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+func (q *Query) handleSelect(query statement, obj interface{}) (*Metadata, error) {
 	var metadata *Metadata = &Metadata{}
-	metadata.Query = query
+	metadata.Query = query.sql
+	metadata.Args = query.values()
 
-	q.log.Debugf("running query against table %s: %s", q.name, metadata.Query)
 	now := time.Now()
-	rows, err := q.db.Query(metadata.Query)
+	rows, err := q.conn().QueryContext(q.ctx, metadata.Query, metadata.Args...)
 	if err != nil {
+		q.logQuery(query, time.Since(now), 0, err)
 		return nil, err
 	}
 
 	metadata.Duration = time.Since(now)
-	q.log.Debugf("query execution took %v", metadata.Duration)
 	defer rows.Close()
 
-	err = q.unmarshalToObj(rows, &obj)
-
-	return metadata, err
+	data, err := q.scanRows(rows)
+	if err != nil {
+		q.logQuery(query, time.Since(now), 0, err)
+		return nil, err
+	}
+	metadata.RowsReturned = len(data)
+	q.logQuery(query, metadata.Duration, metadata.RowsReturned, nil)
+	return metadata, unmarshalRows(data, &obj)
 }
 
-func (q *Query) handleExec(query string) (*Metadata, error) {
+func (q *Query) handleExec(query statement) (*Metadata, error) {
 	var metadata *Metadata = &Metadata{}
 
-	metadata.Query = query
-	q.log.Debugf("running query against table %s: %s", q.name, metadata.Query)
+	metadata.Query = query.sql
+	metadata.Args = query.values()
 	now := time.Now()
-	result, err := q.db.Exec(metadata.Query)
+	result, err := q.conn().ExecContext(q.ctx, metadata.Query, metadata.Args...)
+	metadata.Duration = time.Since(now)
 	if err != nil {
+		q.logQuery(query, metadata.Duration, 0, err)
 		return nil, err
 	}
 
-	metadata.Duration = time.Since(now)
-	q.log.Debugf("query execution took %v", metadata.Duration)
-
 	affected, err := result.RowsAffected()
 	if err == nil {
 		metadata.RowsAffected = int(affected)
+		if q.op == dbtypes.INSERT && (q.conflict == nil || q.conflict.nothing) {
+			metadata.RowsInserted = metadata.RowsAffected
+		}
 	} else {
-		q.log.Errorf("could not read rows affected: %v", err)
+		q.log.Error("could not read rows affected", "table", q.name, "error", err)
 	}
+	q.logQuery(query, metadata.Duration, metadata.RowsAffected, nil)
 	return metadata, nil
 
 }
 
-func (q *Query) constructQuery() (string, error) {
+func (q *Query) constructQuery() (statement, error) {
+	b := &binder{}
+	stmt, err := q.constructSQL(b)
+	if err != nil {
+		return statement{}, err
+	}
+	return statement{sql: stmt, args: b.args}, nil
+}
+
+func (q *Query) constructSQL(b *binder) (string, error) {
 	stmt := ""
 	ignoreNull := false
 	if q.op == dbtypes.INSERT || q.op == dbtypes.UPDATE {
@@ -176,12 +364,30 @@ func (q *Query) constructQuery() (string, error) {
 	var err error
 	if q.op == dbtypes.INSERT {
 		stmt = fmt.Sprintf("%v %v", q.op, q.name)
-		return q.constructInsertQuery(stmt)
+		return q.constructInsertQuery(b, stmt, q.fields)
 	}
 
 	if q.op == dbtypes.DELETE {
-		stmt = q.constructDeleteQuery()
-		return stmt, nil
+		if q.softDeleting() {
+			stmt = q.constructUpdateQuery(b, []field{{Name: softDeleteColumn, Val: Now()}})
+		} else {
+			stmt = q.constructDeleteQuery(b)
+		}
+		returning, err := q.constructReturningClause()
+		return stmt + returning, err
+	}
+
+	if q.op == dbtypes.UPDATE {
+		fields, err := q.constructUpdateFields()
+		if err != nil {
+			return "", err
+		}
+		stmt = q.constructUpdateQuery(b, fields)
+		returning, err := q.constructReturningClause()
+		if err != nil {
+			return "", err
+		}
+		return stmt + returning, nil
 	}
 
 	fields, err := constructFields(q.fields, ignoreNull)
@@ -190,133 +396,171 @@ func (q *Query) constructQuery() (string, error) {
 	}
 
 	if q.op == dbtypes.SELECT {
-		stmt = q.constructGetQuery(fields)
+		stmt = q.constructGetQuery(b, fields)
 	}
-	if q.op == dbtypes.UPDATE {
-		stmt = q.constructUpdateQuery(fields)
+	if q.op == dbtypes.SELECT && len(q.returning) > 0 {
+		return "", fmt.Errorf("Returning cannot be used with Get")
 	}
 
 	return stmt, nil
 }
 
-// Produces a line like below
+// Produces a line like below, with DEFAULT for columns the row left out
 //
-//	('Charlie', 22, 'charlie@example.com')
-func insertValueLine(fields []field) (string, error) {
-	_, vals := groupedList(fields)
-	return vals, nil
+//	($1, $2, DEFAULT)
+func insertValueLine(b *binder, names []string, fields []field) (string, error) {
+	vals := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		vals[f.Name] = f.Val
+	}
+	line := make([]string, len(names))
+	for i, name := range names {
+		val, ok := vals[name]
+		if !ok {
+			line[i] = "DEFAULT"
+			continue
+		}
+		line[i] = b.bind(name, val)
+	}
+	return "(" + strings.Join(line, ", ") + ")", nil
 }
 
-// INSERT INTO users (name, age) VALUES ('Mat', 24);
-func (q *Query) constructInsertQuery(stmt string) (string, error) {
-	// Ensure q.fields is not nil
-	if q.fields == nil {
+// INSERT INTO users (name, age) VALUES ($1, $2), ($3, DEFAULT);
+func (q *Query) constructInsertQuery(b *binder, stmt string, rows interface{}) (string, error) {
+	// Ensure rows is not nil
+	if rows == nil {
 		return "", fmt.Errorf("fields cannot be nil")
 	}
 
-	vals := make([]string, 0)
-
-	// set later
-	var names string
-
-	v := reflect.ValueOf(q.fields)
-	if v.Kind() == reflect.Slice {
-		for i := 0; i < v.Len(); i++ {
-			entry := v.Index(i).Interface()
-
-			parsed, err := constructFields(entry, true)
-			if err != nil {
-				return "", err
-			}
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		v = reflect.ValueOf([]interface{}{rows})
+	}
 
-			if i == 0 {
-				names, _ = groupedList(parsed)
-			}
+	names, err := q.insertColumns(v)
+	if err != nil {
+		return "", err
+	}
 
-			// Convert parsed fields into values
-			val, err := insertValueLine(parsed)
-			if err != nil {
-				return "", err
-			}
-			vals = append(vals, val)
-		}
-	} else {
-		// Handle single entry case
-		parsed, err := constructFields(q.fields, true)
+	vals := make([]string, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		parsed, err := q.constructInsertFields(v.Index(i).Interface())
 		if err != nil {
 			return "", err
 		}
-		names, _ = groupedList(parsed)
-
-		val, err := insertValueLine(parsed)
+		vals[i], err = insertValueLine(b, names, parsed)
 		if err != nil {
 			return "", err
 		}
-		vals = append(vals, val)
 	}
 
 	// Build the SQL statement
-	stmt += fmt.Sprintf(" %s VALUES ", names)
+	stmt += fmt.Sprintf(" (%s) VALUES ", strings.Join(names, ", "))
 	stmt += strings.Join(vals, ", ")
+
+	conflict, err := q.constructConflictClause()
+	if err != nil {
+		return "", err
+	}
+	stmt += conflict
+
+	returning, err := q.constructReturningClause()
+	if err != nil {
+		return "", err
+	}
+	stmt += returning
 	stmt += ";"
 
 	return stmt, nil
 }
 
-// SELECT name, age FROM users WHERE name = 'Mat';
-func (q *Query) constructGetQuery(fields []field) string {
+// constructInsertFields skips zero values so the database can fill in defaults,
+// unless the columns were picked with Columns
+func (q *Query) constructInsertFields(entry interface{}) ([]field, error) {
+	if len(q.columns) > 0 {
+		return pickColumns(entry, q.columns)
+	}
+	return constructFields(entry, true)
+}
+
+// insertColumns returns every column set by any of the rows, so rows that skipped a zero
+// value still line up. Columns are in field order
+func (q *Query) insertColumns(rows reflect.Value) ([]string, error) {
+	set := make(map[string]bool)
+	for i := 0; i < rows.Len(); i++ {
+		parsed, err := q.constructInsertFields(rows.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range parsed {
+			set[f.Name] = true
+		}
+	}
+
+	var names []string
+	for i := 0; i < rows.Len() && len(set) > 0; i++ {
+		all, err := constructFields(rows.Index(i).Interface(), false)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range all {
+			if set[f.Name] {
+				names = append(names, f.Name)
+				delete(set, f.Name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// SELECT name, age FROM users WHERE name = $1;
+func (q *Query) constructGetQuery(b *binder, fields []field) string {
 	names, _ := groupedList(fields)
 	names = strings.ReplaceAll(names, "(", "")
 	names = strings.ReplaceAll(names, ")", "")
 	stmt := fmt.Sprintf("SELECT %s FROM %s", names, q.name)
-	stmt += q.constructWhereClause()
+	stmt += q.constructWhereClause(b)
 	stmt += q.constructLimitClause()
 
 	return stmt
 }
 
-func (q *Query) constructDeleteQuery() string {
+func (q *Query) constructDeleteQuery(b *binder) string {
 	stmt := fmt.Sprintf("%s FROM %s ", q.op, q.name)
-	stmt += q.constructWhereClause()
+	stmt += q.constructWhereClause(b)
 	stmt += q.constructLimitClause()
 	return stmt
 }
 
-func (q *Query) constructUpdateQuery(fields []field) string {
-	names, vals := groupedList(fields)
-	names = strings.ReplaceAll(names, "(", "")
-	names = strings.ReplaceAll(names, ")", "")
-	names = strings.ReplaceAll(names, " ", "")
-	vals = strings.ReplaceAll(vals, "(", "")
-	vals = strings.ReplaceAll(vals, ")", "")
-	vals = strings.ReplaceAll(vals, " ", "")
-
-	nameList := strings.Split(names, ",")
-	valList := strings.Split(vals, ",")
-
+func (q *Query) constructUpdateQuery(b *binder, fields []field) string {
 	stmt := fmt.Sprintf("UPDATE %s SET", q.name)
-	sets := make([]string, len(nameList))
-	for i, name := range nameList {
-		sets[i] = fmt.Sprintf(" %s = %s", name, valList[i])
+	sets := make([]string, len(fields))
+	for i, field := range fields {
+		sets[i] = fmt.Sprintf(" %s = %s", field.Name, b.bind(field.Name, field.Val))
 	}
 
 	stmt += strings.Join(sets, ",")
 
-	stmt += q.constructWhereClause()
+	stmt += q.constructWhereClause(b)
 	stmt += q.constructLimitClause()
 	return stmt
 }
 
-func (q *Query) constructWhereClause() string {
-	if len(q.conditions) == 0 {
+func (q *Query) constructWhereClause(b *binder) string {
+	conditions := append([]Condition{}, q.conditions...)
+	return whereClause(b, append(conditions, q.scopeConditions()...))
+}
+
+func whereClause(b *binder, conditions []Condition) string {
+	if len(conditions) == 0 {
 		return ""
 	}
 	stmt := " WHERE "
-	for i, cond := range q.conditions {
-		if i == len(q.conditions)-1 {
-			stmt += cond.clause
+	for i, cond := range conditions {
+		if i == len(conditions)-1 {
+			stmt += cond.render(b)
 		} else {
-			stmt += fmt.Sprintf("%s AND ", cond.clause)
+			stmt += fmt.Sprintf("%s AND ", cond.render(b))
 		}
 	}
 	return stmt
@@ -330,10 +574,24 @@ func (q *Query) constructLimitClause() string {
 
 }
 
+// timestampLayout writes times the way TIMESTAMP columns show them
+const timestampLayout = "2006-01-02 15:04:05.999999"
+
 func prepareValInsert(val interface{}) string {
-	kind := reflect.TypeOf(val).Kind()
-	if kind == reflect.String {
-		return fmt.Sprintf("'%s'", val)
+	if val == nil {
+		return "NULL"
+	}
+	if expr, ok := val.(Expression); ok {
+		return expr.sql
+	}
+	if t, ok := val.(time.Time); ok {
+		// stored without an offset, so every value is written in UTC
+		return fmt.Sprintf("'%s'", t.UTC().Format(timestampLayout))
+	}
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.String {
+		// a quote inside the value would otherwise end the literal
+		return "'" + strings.ReplaceAll(v.String(), "'", "''") + "'"
 	}
 	return fmt.Sprintf("%v", val)
 }
@@ -363,6 +621,10 @@ func constructFields(rawFields interface{}, ignoreNull bool) ([]field, error) {
 		reflectedValue = reflectedValue.Elem() // Dereference pointer
 	}
 
+	if reflectedValue.Kind() == reflect.Map {
+		return constructMapFields(reflectedValue)
+	}
+
 	reflectedType := reflectedValue.Type()
 
 	// Ensure it's a struct
@@ -370,21 +632,21 @@ func constructFields(rawFields interface{}, ignoreNull bool) ([]field, error) {
 		return nil, fmt.Errorf("expected struct, got %v", reflectedType.Kind())
 	}
 
-	for i := 0; i < reflectedType.NumField(); i++ {
-		f := reflectedType.Field(i)
+	for _, f := range columnFields(reflectedType) {
 		name := f.Tag.Get("json") // Get JSON tag
 
-		if name != "" {
-			val := reflectedValue.Field(i)
+		if name != "" && !isRelation(f) {
+			val := reflectedValue.FieldByIndex(f.Index)
 
-			// If ignoreNull is true, skip fields with zero values
+			// If ignoreNull is true, skip fields with zero values.
+			// Pointers are only skipped when nil so they can be used to set zero values
 			if ignoreNull && val.IsZero() {
 				continue
 			}
 
 			fields = append(fields, field{
 				Name: name,
-				Val:  val.Interface(), // Extract actual value
+				Val:  derefVal(val), // Extract actual value
 			})
 		}
 	}
@@ -395,17 +657,15 @@ func constructFields(rawFields interface{}, ignoreNull bool) ([]field, error) {
 	return fields, nil
 }
 
-// unmarshalToObj unmarshals rows from the database into the provided object(s).
-// If there's exactly one row, it checks that obj is not a slice, then unmarshals it.
-func (q *Query) unmarshalToObj(rows *sql.Rows, obj interface{}) error {
+// scanRows reads every row into a map of column name to value
+func (q *Query) scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 
 	var data []map[string]interface{}
 
 	columns, err := rows.Columns()
 	if err != nil {
-		return fmt.Errorf("failed to get columns: %v", err)
+		return nil, fmt.Errorf("failed to get columns: %v", err)
 	}
-	q.log.Debugf("the following columns were returned: %v", columns)
 
 	values := make([]interface{}, len(columns))
 	for i := range values {
@@ -414,7 +674,7 @@ func (q *Query) unmarshalToObj(rows *sql.Rows, obj interface{}) error {
 
 	for rows.Next() {
 		if err := rows.Scan(values...); err != nil {
-			return fmt.Errorf("failed to scan row: %v", err)
+			return nil, fmt.Errorf("failed to scan row: %v", err)
 		}
 
 		// Create a map for the row
@@ -430,16 +690,17 @@ func (q *Query) unmarshalToObj(rows *sql.Rows, obj interface{}) error {
 
 	// Check for any errors during iteration
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("error iterating over rows: %v", err)
+		return nil, fmt.Errorf("error iterating over rows: %v", err)
 	}
 
-	q.log.Debugf("rows returned %v", len(data))
+	return data, nil
+}
 
+func unmarshalRows(data []map[string]interface{}, obj interface{}) error {
 	bytes, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(bytes, &obj)
-
+	return json.Unmarshal(bytes, obj)
 }
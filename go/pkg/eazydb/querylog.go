@@ -0,0 +1,223 @@
+package eazydb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"path"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// QueryLog controls how queries are logged. Values written by the query builder are sent as
+// args and logged separately from the SQL, eg: WHERE email = $1, and values of fields tagged
+// `db:",sensitive"` are replaced with [REDACTED]. Failed queries are always logged, with their error
+//
+//	eazydb.ClientOptions{QueryLog: eazydb.QueryLog{Redact: []string{"*password*", "token"}, SlowQuery: time.Second}}
+type QueryLog struct {
+	// Redact also hides the values of columns matching these patterns, see path.Match
+	Redact []string
+	// SampleRate is the fraction of queries logged at debug level, eg: 0.1. 0 logs every query
+	SampleRate float64
+	// SlowQuery logs queries that take at least this long as warnings, whether sampled or not.
+	// 0 turns slow query warnings off
+	SlowQuery time.Duration
+}
+
+func (p QueryLog) validate() error {
+	if p.SampleRate < 0 || p.SampleRate > 1 {
+		return fmt.Errorf("QueryLog.SampleRate must be between 0 and 1, got %v", p.SampleRate)
+	}
+	if p.SlowQuery < 0 {
+		return fmt.Errorf("QueryLog.SlowQuery cannot be negative, got %v", p.SlowQuery)
+	}
+	for _, pattern := range p.Redact {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("QueryLog.Redact has an invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// queryLogger is the client's logger along with its QueryLog policy
+type queryLogger struct {
+	Logger
+	policy QueryLog
+}
+
+func newQueryLogger(log Logger, policy QueryLog) *queryLogger {
+	patterns := make([]string, len(policy.Redact))
+	for i, pattern := range policy.Redact {
+		patterns[i] = strings.ToLower(pattern)
+	}
+	policy.Redact = patterns
+	return &queryLogger{Logger: log, policy: policy}
+}
+
+// queryEntry is a statement that has run, or failed to
+type queryEntry struct {
+	table    string
+	op       string
+	query    string
+	args     []arg
+	duration time.Duration
+	rows     int
+	err      error
+	// redactor builds the check for whether the values of a column should be hidden. It is only
+	// called if the query is logged, Redact patterns are used when it is nil
+	redactor func() func(column string) bool
+}
+
+// query logs a statement as a warning if it was slow, otherwise at debug level if it failed
+// or is sampled
+func (l *queryLogger) query(e queryEntry) {
+	slow := l.policy.SlowQuery > 0 && e.duration >= l.policy.SlowQuery
+	if !slow && (!l.debugEnabled() || (e.err == nil && !l.sampled())) {
+		return
+	}
+
+	op := e.op
+	if words := strings.Fields(op); len(words) > 0 {
+		op = strings.ToUpper(words[0])
+	}
+	fields := []any{"table", e.table, "op", op, "duration", e.duration, "rows", e.rows, "query", e.query}
+	if len(e.args) > 0 {
+		redact := func(column string) bool { return l.sensitive(column, nil) }
+		if e.redactor != nil {
+			redact = e.redactor()
+		}
+		fields = append(fields, "args", logArgs(e.args, redact))
+	}
+	if e.err != nil {
+		fields = append(fields, "error", e.err)
+	}
+
+	if slow {
+		l.Warn("slow query", append(fields, "threshold", l.policy.SlowQuery)...)
+		return
+	}
+	l.Debug("query", fields...)
+}
+
+func (l *queryLogger) sampled() bool {
+	rate := l.policy.SampleRate
+	return rate == 0 || rate >= 1 || rand.Float64() < rate
+}
+
+// debugEnabled asks loggers that can tell, such as slog, whether debug logs are kept,
+// so queries aren't formatted only to be dropped
+func (l *queryLogger) debugEnabled() bool {
+	if leveled, ok := l.Logger.(interface {
+		Enabled(context.Context, slog.Level) bool
+	}); ok {
+		return leveled.Enabled(context.Background(), slog.LevelDebug)
+	}
+	return true
+}
+
+// sensitive reports whether a column is tagged sensitive or matches a Redact pattern
+func (l *queryLogger) sensitive(column string, tagged map[string]bool) bool {
+	if tagged[column] {
+		return true
+	}
+	column = strings.ToLower(column)
+	for _, pattern := range l.policy.Redact {
+		if ok, _ := path.Match(pattern, column); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// arg is a value bound to a placeholder, along with the column it is written to or compared with
+type arg struct {
+	column string
+	val    interface{}
+}
+
+// statement is SQL along with the values of its $n placeholders
+type statement struct {
+	sql  string
+	args []arg
+}
+
+// values returns the args of the statement the way database/sql takes them
+func (s statement) values() []interface{} {
+	vals := make([]interface{}, len(s.args))
+	for i, a := range s.args {
+		vals[i] = a.val
+	}
+	return vals
+}
+
+// binder collects the values of a statement as it is built, numbering their placeholders
+// in the order they are written
+type binder struct {
+	args []arg
+	// inline writes values into the SQL instead, for DDL which can't take parameters
+	inline bool
+}
+
+// bind returns the placeholder of a value written to or compared with column. NULL and
+// expressions are SQL rather than values, so they are written as they are
+func (b *binder) bind(column string, val interface{}) string {
+	if _, ok := val.(Expression); ok || val == nil || b.inline {
+		return prepareValInsert(val)
+	}
+	if t, ok := val.(time.Time); ok {
+		// stored without an offset, so every value is written in UTC
+		val = t.UTC()
+	}
+	b.args = append(b.args, arg{column: column, val: val})
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// logArgs formats the args of a statement for the log, hiding those of redacted columns
+func logArgs(args []arg, redact func(column string) bool) []string {
+	vals := make([]string, len(args))
+	for i, a := range args {
+		if redact(a.column) {
+			vals[i] = redacted
+			continue
+		}
+		if t, ok := a.val.(time.Time); ok {
+			vals[i] = t.Format(timestampLayout)
+			continue
+		}
+		vals[i] = fmt.Sprint(a.val)
+	}
+	return vals
+}
+
+// redactor returns which columns to hide when logging the query: those tagged sensitive on
+// the rows it reads or writes, and those matching a Redact pattern
+func (q *Query) redactor() func(column string) bool {
+	tagged := make(map[string]bool)
+	if t := rowType(q.fields); t != nil {
+		for _, f := range columnFields(t) {
+			if name := f.Tag.Get("json"); name != "" && parseTag(f).sensitive {
+				tagged[name] = true
+			}
+		}
+	}
+	return func(column string) bool {
+		return q.log.sensitive(column, tagged)
+	}
+}
+
+// logQuery logs a statement run by the query, err is set if it failed
+func (q *Query) logQuery(stmt statement, duration time.Duration, rows int, err error) {
+	q.log.query(queryEntry{
+		table:    q.name,
+		op:       string(q.op),
+		query:    stmt.sql,
+		args:     stmt.args,
+		duration: duration,
+		rows:     rows,
+		err:      err,
+		redactor: q.redactor,
+	})
+}
@@ -0,0 +1,267 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// queries returns the query logs, leaving out the client's other logs
+func (l *recordLogger) queries() []logEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var entries []logEntry
+	for _, e := range l.entries {
+		if e.msg == "query" || e.msg == "slow query" {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func loggedClient(t *testing.T, policy QueryLog) (*Client, *fakeDB, *recordLogger) {
+	t.Helper()
+	c, fake := newTestClient(t)
+	rec := &recordLogger{}
+	c.log = newQueryLogger(rec, policy)
+	return c, fake, rec
+}
+
+type account struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Pin   int    `json:"pin" db:",sensitive"`
+}
+
+func TestQueryLogArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		redact []string
+		run    func(c *Client) error
+		query  string
+		args   []string
+	}{
+		{
+			name: "sensitive int is redacted",
+			run: func(c *Client) error {
+				_, err := c.Table("accounts").Add(account{Email: "mat@x.io", Pin: 1234}).Exec()
+				return err
+			},
+			query: "INSERT INTO accounts (email, pin) VALUES ($1, $2);",
+			args:  []string{"mat@x.io", redacted},
+		},
+		{
+			name:   "int compared with a redacted column",
+			redact: []string{"p?n"},
+			run: func(c *Client) error {
+				_, err := c.Table("accounts").Get(account{}).Where(*Int("pin").Equals(1234)).Exec(&[]account{})
+				return err
+			},
+			query: "SELECT email, name, pin FROM accounts WHERE pin = $1",
+			args:  []string{redacted},
+		},
+		{
+			name:   "IN list of a redacted column",
+			redact: []string{"EMAIL"},
+			run: func(c *Client) error {
+				_, err := c.Model("accounts", "email").DeleteByKey(context.Background(), "mat@x.io", "ann@x.io")
+				return err
+			},
+			query: "DELETE FROM accounts  WHERE email IN ($1, $2)",
+			args:  []string{redacted, redacted},
+		},
+		{
+			name: "quotes stay inside their value",
+			run: func(c *Client) error {
+				_, err := c.Table("accounts").Update(account{Name: "O'Brien, 'Mat'"}).Where(*String("name").Equals("it's")).Exec()
+				return err
+			},
+			query: "UPDATE accounts SET name = $1 WHERE name = $2",
+			args:  []string{"O'Brien, 'Mat'", "it's"},
+		},
+		{
+			name:   "conditions joined with Or are numbered in order",
+			redact: []string{"pin"},
+			run: func(c *Client) error {
+				_, err := c.Table("accounts").Get(account{}).Where(*String("name").Equals("a").Or(*Int("pin").Equals(1)), *String("email").Equals("b")).Exec(&[]account{})
+				return err
+			},
+			query: "SELECT email, name, pin FROM accounts WHERE (name = $1 OR pin = $2) AND email = $3",
+			args:  []string{"a", redacted, "b"},
+		},
+		{
+			name: "values that look like columns are left alone",
+			run: func(c *Client) error {
+				_, err := c.Table("accounts").Get(account{}).Where(*String("name").Equals("email = 'x'")).Exec(&[]account{})
+				return err
+			},
+			query: "SELECT email, name, pin FROM accounts WHERE name = $1",
+			args:  []string{"email = 'x'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _, rec := loggedClient(t, QueryLog{Redact: tt.redact})
+			if err := tt.run(c); err != nil {
+				t.Fatal(err)
+			}
+			logs := rec.queries()
+			if len(logs) != 1 {
+				t.Fatalf("got %d query logs, want 1", len(logs))
+			}
+			if got := logs[0].fields["query"]; got != tt.query {
+				t.Errorf("got  %s\nwant %s", got, tt.query)
+			}
+			if got, _ := logs[0].fields["args"].([]string); !reflect.DeepEqual(got, tt.args) {
+				t.Errorf("got args %q, want %q", got, tt.args)
+			}
+		})
+	}
+}
+
+func TestValuesAreSentAsArgs(t *testing.T) {
+	c, fake, _ := loggedClient(t, QueryLog{})
+	if _, err := c.Table("accounts").Add(account{Name: "O'Brien"}).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Table("accounts").Delete().Where(*String("name").Equals("it's'; DROP TABLE accounts; --")).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"INSERT INTO accounts (name) VALUES ($1); [O'Brien]",
+		"DELETE FROM accounts  WHERE name = $1 [it's'; DROP TABLE accounts; --]",
+	}
+	if got := fake.queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestFailedQueriesAreLogged(t *testing.T) {
+	c, fake, rec := loggedClient(t, QueryLog{SampleRate: 0.0001, SlowQuery: time.Hour})
+	boom := errors.New("boom")
+	fake.respond = func(string) fakeResult { return fakeResult{err: boom} }
+
+	c.Table("accounts").Get(account{}).Exec(&[]account{})
+	c.Table("accounts").Delete().Where(*Int("pin").Equals(1)).Exec()
+	c.Table("accounts").Add(account{Email: "mat@x.io"}).Returning("pin").Exec()
+
+	logs := rec.queries()
+	if len(logs) != 3 {
+		t.Fatalf("got %d query logs, want every failure logged", len(logs))
+	}
+	for _, log := range logs {
+		if log.level != "debug" || log.fields["error"] != boom {
+			t.Errorf("got %+v", log)
+		}
+	}
+
+	fake.respond = nil
+	c.Table("accounts").Get(account{}).Exec(&[]account{})
+	if len(rec.queries()) > 4 {
+		t.Errorf("successful queries should still be sampled")
+	}
+}
+
+func TestCopyBatchesAreLogged(t *testing.T) {
+	c, fake, rec := loggedClient(t, QueryLog{})
+	accounts := []account{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	if _, err := c.Table("accounts").Add(accounts).BatchSize(2).Copy().Exec(); err != nil {
+		t.Fatal(err)
+	}
+	copyIn := `COPY "accounts" ("name") FROM STDIN`
+	logs := rec.queries()
+	if len(logs) != 2 {
+		t.Fatalf("got %d query logs, want one per batch", len(logs))
+	}
+	for i, rows := range []int{2, 1} {
+		if logs[i].fields["query"] != copyIn || logs[i].fields["rows"] != rows || logs[i].fields["error"] != nil {
+			t.Errorf("got batch log %+v, want %d rows", logs[i], rows)
+		}
+	}
+
+	boom := errors.New("boom")
+	fake.respond = func(string) fakeResult { return fakeResult{err: boom} }
+	if _, err := c.Table("accounts").Add(accounts).Copy().Exec(); !errors.Is(err, boom) {
+		t.Fatalf("got error %v, want %v", err, boom)
+	}
+	logs = rec.queries()
+	if failed := logs[len(logs)-1]; len(logs) != 3 || failed.fields["query"] != copyIn || failed.fields["error"] != boom {
+		t.Errorf("got %+v, want the failed batch logged with its error", logs)
+	}
+}
+
+func TestSlowFailedQueryWarns(t *testing.T) {
+	c, fake, rec := loggedClient(t, QueryLog{Redact: []string{"pin"}, SlowQuery: time.Nanosecond})
+	fake.respond = func(string) fakeResult {
+		time.Sleep(time.Millisecond)
+		return fakeResult{err: context.DeadlineExceeded}
+	}
+
+	c.Table("accounts").Delete().Where(*Int("pin").Equals(1)).Exec()
+	logs := rec.queries()
+	if len(logs) != 1 || logs[0].level != "warn" || logs[0].fields["error"] != context.DeadlineExceeded {
+		t.Fatalf("got %+v", logs)
+	}
+	if got := logs[0].fields["args"]; !reflect.DeepEqual(got, []string{redacted}) {
+		t.Errorf("a slow query should still be redacted, got args %v", got)
+	}
+}
+
+func TestPreloadIsLoggedAsAQuery(t *testing.T) {
+	c, fake, rec := loggedClient(t, QueryLog{Redact: []string{"user_id"}})
+	fake.respond = func(query string) fakeResult {
+		if strings.Contains(query, "FROM users") {
+			return fakeResult{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "Mat"}, {int64(2), "Ann"}}}
+		}
+		return fakeResult{columns: []string{"id", "total", "user_id"}}
+	}
+
+	if _, err := c.Table("users").Get(relUser{}).Preload("Orders").Exec(&[]relUser{}); err != nil {
+		t.Fatal(err)
+	}
+	logs := rec.queries()
+	if len(logs) != 2 {
+		t.Fatalf("got %d query logs, want 2", len(logs))
+	}
+	preload := logs[1]
+	if preload.fields["table"] != "orders" || preload.fields["query"] != "SELECT orders.id, orders.total, orders.user_id FROM orders WHERE user_id IN ($1, $2)" {
+		t.Errorf("got %+v", preload.fields)
+	}
+	if got := preload.fields["args"]; !reflect.DeepEqual(got, []string{redacted, redacted}) {
+		t.Errorf("got args %v", got)
+	}
+}
+
+func TestMigrationsAreLoggedAsQueries(t *testing.T) {
+	c, fake, rec := loggedClient(t, QueryLog{})
+	fake.respond = func(query string) fakeResult {
+		switch {
+		case strings.Contains(query, "information_schema.columns"):
+			return fakeResult{columns: []string{"column_name"}, rows: [][]driver.Value{{"email"}}}
+		case strings.Contains(query, "pg_constraint"):
+			return fakeResult{columns: []string{"conname", "columns", "relname", "ref_columns", "confdeltype", "confupdtype"}}
+		case strings.HasPrefix(query, "CREATE INDEX"):
+			return fakeResult{err: errors.New("boom")}
+		}
+		return defaultResult(query)
+	}
+
+	if _, err := c.NewTable("users").Fields(migratedUser{}).AddNewFields().Exec(); err == nil {
+		t.Fatal("want the index error")
+	}
+	var ops []string
+	for _, log := range rec.queries() {
+		ops = append(ops, fmt.Sprint(log.fields["op"]))
+		if strings.HasPrefix(fmt.Sprint(log.fields["query"]), "CREATE INDEX") && log.fields["error"] == nil {
+			t.Errorf("the failed index was logged without its error")
+		}
+	}
+	if want := []string{"CREATE", "ALTER", "ALTER", "CREATE"}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("got ops %v, want %v", ops, want)
+	}
+}
@@ -0,0 +1,272 @@
+package eazydb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+type relationKind string
+
+const (
+	hasOne     relationKind = "hasone"
+	hasMany    relationKind = "hasmany"
+	manyToMany relationKind = "manytomany"
+)
+
+// relation is declared with a `db` tag on a struct or slice field
+//
+//	Profile *Profile `json:"profile" db:",hasone=profiles(user_id)"`
+//	Orders  []Order  `json:"orders" db:",hasmany=orders(user_id)"`
+//	Tags    []Tag    `json:"tags" db:",manytomany=tags,through=user_tags(user_id,tag_id)"`
+//
+// The parent and related tables are matched on their id column unless key= or refkey= is set
+type relation struct {
+	kind  relationKind
+	table string
+	// column of table that holds the parent key, unused for many to many
+	fk string
+	// key of the parent
+	key string
+	// many to many only
+	through    string
+	throughFK  string
+	throughRef string
+	refKey     string
+}
+
+func parseRelation(opts tagOptions) (*relation, error) {
+	rel := &relation{key: "id", refKey: "id"}
+	if opts.key != "" {
+		rel.key = opts.key
+	}
+	if opts.refKey != "" {
+		rel.refKey = opts.refKey
+	}
+
+	var err error
+	switch {
+	case opts.hasOne != "":
+		rel.kind = hasOne
+		rel.table, rel.fk, err = parseTableColumns(opts.hasOne, 1)
+	case opts.hasMany != "":
+		rel.kind = hasMany
+		rel.table, rel.fk, err = parseTableColumns(opts.hasMany, 1)
+	case opts.manyToMany != "":
+		rel.kind = manyToMany
+		rel.table = opts.manyToMany
+		if opts.through == "" {
+			return nil, fmt.Errorf("manytomany requires through=join_table(parent_column,related_column)")
+		}
+		var cols string
+		rel.through, cols, err = parseTableColumns(opts.through, 2)
+		if err == nil {
+			parts := strings.Split(cols, ",")
+			rel.throughFK, rel.throughRef = parts[0], parts[1]
+		}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// parseTableColumns reads table(a) or table(a,b)
+func parseTableColumns(s string, count int) (string, string, error) {
+	open := strings.Index(s, "(")
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return "", "", fmt.Errorf("expected table(column), got %v", s)
+	}
+	cols := s[open+1 : len(s)-1]
+	if len(strings.Split(cols, ",")) != count {
+		return "", "", fmt.Errorf("expected %v columns in %v", count, s)
+	}
+	return s[:open], cols, nil
+}
+
+// isRelation reports whether a field holds related records rather than a column
+func isRelation(f reflect.StructField) bool {
+	opts := parseTag(f)
+	return opts.hasOne != "" || opts.hasMany != "" || opts.manyToMany != ""
+}
+
+// Preload fills the given relation fields on the returned structs, using one query per relation
+//
+//	var users []User
+//	table.Get(User{}).Preload("Orders", "Tags").Exec(&users)
+func (q *Query) Preload(fields ...string) *Query {
+	if q.op != dbtypes.SELECT {
+		q.err = fmt.Errorf("Preload can only be used with Get, table operation is %v", q.op)
+		return q
+	}
+	q.preloads = append(q.preloads, fields...)
+	return q
+}
+
+// loadRelations runs a batched query per preloaded relation and fills the fields of obj,
+// which is a pointer to a struct or a slice of structs
+func (q *Query) loadRelations(obj interface{}) error {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	var parents []reflect.Value
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			parents = append(parents, v.Index(i))
+		}
+	case reflect.Struct:
+		parents = append(parents, v)
+	default:
+		return fmt.Errorf("Preload needs a pointer to a struct or slice of structs, got %T", obj)
+	}
+	if len(parents) == 0 {
+		return nil
+	}
+
+	for _, name := range q.preloads {
+		sf, ok := parents[0].Type().FieldByName(name)
+		if !ok {
+			return fmt.Errorf("%v has no field %v to preload", parents[0].Type(), name)
+		}
+		rel, err := parseRelation(parseTag(sf))
+		if err != nil {
+			return fmt.Errorf("%v has an invalid relation: %v", name, err)
+		}
+		if rel == nil {
+			return fmt.Errorf("%v is not a relation, tag it with hasone, hasmany or manytomany", name)
+		}
+		if err := q.loadRelation(parents, sf, rel); err != nil {
+			return fmt.Errorf("could not preload %v: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// marks the parent key of a many to many row
+const relationParentColumn = "eazydb_parent"
+
+func (q *Query) loadRelation(parents []reflect.Value, sf reflect.StructField, rel *relation) error {
+	// the column the parent keys are compared with
+	keyColumn := rel.fk
+	if rel.kind == manyToMany {
+		keyColumn = rel.throughFK
+	}
+	b := &binder{}
+	keys := make([]string, 0, len(parents))
+	seen := make(map[string]bool)
+	for _, parent := range parents {
+		key, err := fieldByColumn(parent, rel.key)
+		if err != nil {
+			return err
+		}
+		if key == nil || seen[fmt.Sprint(key)] {
+			continue
+		}
+		seen[fmt.Sprint(key)] = true
+		keys = append(keys, b.bind(keyColumn, key))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	elem := sf.Type
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice {
+		elem = elem.Elem()
+	}
+	fields, err := constructFields(reflect.New(elem).Interface(), false)
+	if err != nil {
+		return err
+	}
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = fmt.Sprintf("%s.%s", rel.table, f.Name)
+	}
+
+	var stmt, parentColumn string
+	switch rel.kind {
+	case manyToMany:
+		parentColumn = relationParentColumn
+		stmt = fmt.Sprintf("SELECT %s, %s.%s AS %s FROM %s JOIN %s ON %s.%s = %s.%s WHERE %s.%s IN (%s)",
+			strings.Join(columns, ", "), rel.through, rel.throughFK, relationParentColumn,
+			rel.table, rel.through, rel.through, rel.throughRef, rel.table, rel.refKey,
+			rel.through, rel.throughFK, strings.Join(keys, ", "))
+		if q.softDeletes.has(rel.table) {
+			stmt += fmt.Sprintf(" AND %s.%s IS NULL", rel.table, softDeleteColumn)
+		}
+	default:
+		parentColumn = rel.fk
+		if !hasField(fields, rel.fk) {
+			// the key is needed to match rows to their parent even if the struct doesn't keep it
+			columns = append(columns, fmt.Sprintf("%s.%s", rel.table, rel.fk))
+		}
+		stmt = fmt.Sprintf("SELECT %s FROM %s WHERE %s IN (%s)",
+			strings.Join(columns, ", "), rel.table, rel.fk, strings.Join(keys, ", "))
+		if q.softDeletes.has(rel.table) {
+			stmt += fmt.Sprintf(" AND %s IS NULL", softDeleteColumn)
+		}
+	}
+
+	query := statement{sql: stmt, args: b.args}
+	now := time.Now()
+	rows, err := q.conn().QueryContext(q.ctx, query.sql, query.values()...)
+	if err != nil {
+		q.log.query(queryEntry{table: rel.table, op: string(dbtypes.SELECT), query: query.sql, args: query.args, duration: time.Since(now), err: err})
+		return err
+	}
+	defer rows.Close()
+	data, err := q.scanRows(rows)
+	q.log.query(queryEntry{table: rel.table, op: string(dbtypes.SELECT), query: query.sql, args: query.args, duration: time.Since(now), rows: len(data), err: err})
+	if err != nil {
+		return err
+	}
+
+	grouped := make(map[string][]map[string]interface{})
+	for _, row := range data {
+		key := fmt.Sprint(row[parentColumn])
+		if rel.kind == manyToMany {
+			delete(row, relationParentColumn)
+		}
+		grouped[key] = append(grouped[key], row)
+	}
+
+	for _, parent := range parents {
+		key, _ := fieldByColumn(parent, rel.key)
+		related := grouped[fmt.Sprint(key)]
+		target := parent.FieldByIndex(sf.Index)
+
+		if sf.Type.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(sf.Type, 0, len(related)))
+			if len(related) > 0 {
+				if err := unmarshalRows(related, target.Addr().Interface()); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		target.Set(reflect.Zero(sf.Type))
+		if len(related) > 0 {
+			if err := unmarshalRow(related[0], target.Addr().Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldByColumn returns the value of the field tagged with the column name
+func fieldByColumn(v reflect.Value, column string) (interface{}, error) {
+	for _, f := range columnFields(v.Type()) {
+		if f.Tag.Get("json") == column {
+			return derefVal(v.FieldByIndex(f.Index)), nil
+		}
+	}
+	return nil, fmt.Errorf("%v has no field tagged with json:\"%s\"", v.Type(), column)
+}
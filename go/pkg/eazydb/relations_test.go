@@ -0,0 +1,117 @@
+package eazydb
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type relProfile struct {
+	UserID int    `json:"user_id"`
+	Bio    string `json:"bio"`
+}
+
+type relOrder struct {
+	ID    int `json:"id"`
+	Total int `json:"total"`
+}
+
+type relTag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type relUser struct {
+	ID      int         `json:"id"`
+	Name    string      `json:"name"`
+	Profile *relProfile `json:"profile" db:",hasone=profiles(user_id)"`
+	Orders  []relOrder  `json:"orders" db:",hasmany=orders(user_id)"`
+	Tags    []relTag    `json:"tags" db:",manytomany=tags,through=user_tags(user_id,tag_id)"`
+}
+
+func TestPreload(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(query string) fakeResult {
+		switch {
+		case strings.Contains(query, "FROM users"):
+			return fakeResult{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "Mat"}, {int64(2), "Ann"}}}
+		case strings.Contains(query, "FROM profiles"):
+			return fakeResult{columns: []string{"user_id", "bio"}, rows: [][]driver.Value{{int64(1), "hi"}}}
+		case strings.Contains(query, "FROM orders"):
+			return fakeResult{columns: []string{"id", "total", "user_id"}, rows: [][]driver.Value{{int64(10), int64(5), int64(1)}, {int64(11), int64(7), int64(1)}}}
+		case strings.Contains(query, "FROM tags"):
+			return fakeResult{columns: []string{"id", "name", relationParentColumn}, rows: [][]driver.Value{{int64(3), "go", int64(2)}}}
+		}
+		return defaultResult(query)
+	}
+
+	var users []relUser
+	if _, err := c.Table("users").Get(relUser{}).Preload("Profile", "Orders", "Tags").Exec(&users); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []relUser{
+		{ID: 1, Name: "Mat", Profile: &relProfile{UserID: 1, Bio: "hi"}, Orders: []relOrder{{10, 5}, {11, 7}}, Tags: []relTag{}},
+		{ID: 2, Name: "Ann", Orders: []relOrder{}, Tags: []relTag{{3, "go"}}},
+	}
+	if !reflect.DeepEqual(users, want) {
+		t.Errorf("got  %+v\nwant %+v", users, want)
+	}
+
+	queries := fake.queries()
+	wantQueries := []string{
+		"SELECT profiles.user_id, profiles.bio FROM profiles WHERE user_id IN ($1, $2) [1 2]",
+		"SELECT orders.id, orders.total, orders.user_id FROM orders WHERE user_id IN ($1, $2) [1 2]",
+		"SELECT tags.id, tags.name, user_tags.user_id AS eazydb_parent FROM tags JOIN user_tags ON user_tags.tag_id = tags.id WHERE user_tags.user_id IN ($1, $2) [1 2]",
+	}
+	if len(queries) != 4 || !reflect.DeepEqual(queries[1:], wantQueries) {
+		t.Errorf("got  %q\nwant a query for users then %q", queries, wantQueries)
+	}
+}
+
+func TestPreloadSkipsSoftDeleted(t *testing.T) {
+	c, fake := newTestClient(t)
+	c.softDeletes.add("orders")
+	fake.respond = func(query string) fakeResult {
+		if strings.Contains(query, "FROM users") {
+			return fakeResult{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "Mat"}}}
+		}
+		return fakeResult{columns: []string{"id", "total", "user_id"}}
+	}
+
+	var users []relUser
+	if _, err := c.Table("users").Get(relUser{}).Preload("Orders").Exec(&users); err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT orders.id, orders.total, orders.user_id FROM orders WHERE user_id IN ($1) AND deleted_at IS NULL [1]"
+	if queries := fake.queries(); queries[len(queries)-1] != want {
+		t.Errorf("got  %q\nwant %q", queries[len(queries)-1], want)
+	}
+}
+
+func TestPreloadErrors(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(string) fakeResult {
+		return fakeResult{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(1), "Mat"}}}
+	}
+
+	tests := []struct {
+		name  string
+		query *Query
+		err   string
+	}{
+		{"on add", c.Table("users").Add(relUser{Name: "Mat"}).Preload("Orders"), "Preload can only be used with Get"},
+		{"unknown field", c.Table("users").Get(relUser{}).Preload("Friends"), "has no field Friends"},
+		{"not a relation", c.Table("users").Get(relUser{}).Preload("Name"), "Name is not a relation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []relUser
+			_, err := tt.query.Exec(&users)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
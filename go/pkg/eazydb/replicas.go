@@ -0,0 +1,301 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReplicaRouting decides which healthy replica a read goes to
+type ReplicaRouting string
+
+const (
+	// RoundRobin spreads reads evenly over the healthy replicas
+	RoundRobin ReplicaRouting = "round-robin"
+	// LeastLatency sends reads to the replica with the fastest last health check
+	LeastLatency ReplicaRouting = "least-latency"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	maxHealthCheckTimeout      = 5 * time.Second
+)
+
+type replica struct {
+	db      *sql.DB
+	name    string
+	healthy atomic.Bool
+	latency atomic.Int64
+}
+
+// replicaSet routes reads to read replicas, checking their health in the background
+type replicaSet struct {
+	replicas []*replica
+	routing  ReplicaRouting
+	interval time.Duration
+	next     atomic.Uint64
+	log      Logger
+	stop     chan struct{}
+	done     sync.WaitGroup
+	closed   sync.Once
+	closeErr error
+}
+
+// replicaOptions fills the fields left empty on a replica from the primary's options,
+// including those set in the primary's DSN, so a replica usually only needs a Host or DSN
+func replicaOptions(primary *ClientOptions, r ClientOptions) (*ClientOptions, error) {
+	inherit := func(val *string, from string) {
+		if *val == "" {
+			*val = from
+		}
+	}
+	primary, err := primary.withDSNFields()
+	if err != nil {
+		return nil, err
+	}
+	// a replica with its own password or DSN doesn't use the primary's credential provider
+	if r.Credentials == nil && r.Password == "" && r.DSN == "" {
+		r.Credentials = primary.Credentials
+	}
+	if r.DSN == "" {
+		inherit(&r.User, primary.User)
+		inherit(&r.Password, primary.Password)
+		inherit(&r.Port, primary.Port)
+		inherit(&r.Name, primary.Name)
+	}
+	inherit(&r.SSLMode, primary.SSLMode)
+	inherit(&r.SSLCert, primary.SSLCert)
+	inherit(&r.SSLKey, primary.SSLKey)
+	inherit(&r.SSLRootCert, primary.SSLRootCert)
+	inherit(&r.ApplicationName, primary.ApplicationName)
+	inherit(&r.SearchPath, primary.SearchPath)
+	if r.Type == "" {
+		r.Type = primary.Type
+	}
+	if r.ConnectTimeout == 0 {
+		r.ConnectTimeout = primary.ConnectTimeout
+	}
+	if r.MaxOpenConns == 0 {
+		r.MaxOpenConns = primary.MaxOpenConns
+	}
+	if r.MaxIdleConns == 0 {
+		r.MaxIdleConns = primary.MaxIdleConns
+	}
+	if r.ConnMaxLifetime == 0 {
+		r.ConnMaxLifetime = primary.ConnMaxLifetime
+	}
+	if r.ConnMaxIdleTime == 0 {
+		r.ConnMaxIdleTime = primary.ConnMaxIdleTime
+	}
+	return &r, nil
+}
+
+// withDSNFields returns a copy of the options with the fields left empty read from the DSN,
+// so they can be inherited by a replica. The host is left out as a replica has its own
+func (opt *ClientOptions) withDSNFields() (*ClientOptions, error) {
+	other := *opt
+	if opt.DSN == "" {
+		return &other, nil
+	}
+
+	var params map[string]string
+	if strings.Contains(opt.DSN, "://") {
+		u, err := url.Parse(opt.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse DSN: %v", err)
+		}
+		params = make(map[string]string)
+		for k, v := range u.Query() {
+			params[k] = v[0]
+		}
+		if u.User != nil {
+			params["user"] = u.User.Username()
+			params["password"], _ = u.User.Password()
+		}
+		params["port"] = u.Port()
+		params["dbname"] = strings.TrimPrefix(u.Path, "/")
+	} else {
+		var err error
+		params, err = parseParams(opt.DSN)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for key, val := range map[string]*string{
+		"user":             &other.User,
+		"password":         &other.Password,
+		"port":             &other.Port,
+		"dbname":           &other.Name,
+		"sslmode":          &other.SSLMode,
+		"sslcert":          &other.SSLCert,
+		"sslkey":           &other.SSLKey,
+		"sslrootcert":      &other.SSLRootCert,
+		"application_name": &other.ApplicationName,
+		"search_path":      &other.SearchPath,
+	} {
+		if *val == "" {
+			*val = params[key]
+		}
+	}
+	return &other, nil
+}
+
+// openReplicas opens a pool per replica and runs a first health check. An unreachable
+// replica doesn't stop the client from starting, reads skip it until it is healthy
+func openReplicas(opt *ClientOptions, log Logger) (*replicaSet, error) {
+	if len(opt.Replicas) == 0 {
+		return nil, nil
+	}
+	s := &replicaSet{
+		routing:  opt.ReplicaRouting,
+		interval: opt.ReplicaHealthCheck,
+		log:      log,
+		stop:     make(chan struct{}),
+	}
+	if s.routing == "" {
+		s.routing = RoundRobin
+	}
+	if s.interval == 0 {
+		s.interval = defaultHealthCheckInterval
+	}
+
+	for i, r := range opt.Replicas {
+		ropt, err := replicaOptions(opt, r)
+		if err != nil {
+			s.closeAll()
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		if err := validateOptions(ropt); err != nil {
+			s.closeAll()
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		db, err := openDB(ropt)
+		if err != nil {
+			s.closeAll()
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		configurePool(db, ropt)
+		name := ropt.Host
+		if name == "" {
+			name = fmt.Sprintf("replica %d", i)
+		}
+		s.replicas = append(s.replicas, &replica{db: db, name: name})
+	}
+
+	s.check()
+	for _, r := range s.replicas {
+		if !r.healthy.Load() {
+			s.log.Warn("replica is unreachable, reads will skip it until it is healthy", "replica", r.name)
+		}
+	}
+	s.done.Add(1)
+	go s.run()
+	return s, nil
+}
+
+func (s *replicaSet) run() {
+	defer s.done.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.check()
+		}
+	}
+}
+
+// check pings every replica, recording its latency and logging when its health changes
+func (s *replicaSet) check() {
+	timeout := s.interval
+	if timeout > maxHealthCheckTimeout {
+		timeout = maxHealthCheckTimeout
+	}
+
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := r.db.PingContext(ctx)
+			r.latency.Store(int64(time.Since(start)))
+			was := r.healthy.Swap(err == nil)
+			switch {
+			case err != nil && was:
+				s.log.Warn("replica is unhealthy, reads are going elsewhere", "replica", r.name, "error", err)
+			case err != nil:
+				s.log.Debug("replica is still unhealthy", "replica", r.name, "error", err)
+			case !was:
+				s.log.Info("replica is healthy", "replica", r.name, "latency", time.Duration(r.latency.Load()))
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+// pick returns the replica a read should use, or nil to use the primary when none are healthy
+func (s *replicaSet) pick() *sql.DB {
+	if s == nil {
+		return nil
+	}
+
+	if s.routing == LeastLatency {
+		var best *replica
+		for _, r := range s.replicas {
+			if r.healthy.Load() && (best == nil || r.latency.Load() < best.latency.Load()) {
+				best = r
+			}
+		}
+		if best == nil {
+			return nil
+		}
+		return best.db
+	}
+
+	n := len(s.replicas)
+	start := int(s.next.Add(1) % uint64(n))
+	for i := 0; i < n; i++ {
+		if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.db
+		}
+	}
+	return nil
+}
+
+// close stops the health checks and closes the replica pools, later calls return the first result
+func (s *replicaSet) close() error {
+	if s == nil {
+		return nil
+	}
+	s.closed.Do(func() {
+		close(s.stop)
+		s.done.Wait()
+		s.closeErr = s.closeAll()
+	})
+	return s.closeErr
+}
+
+func (s *replicaSet) closeAll() error {
+	var errs []error
+	for _, r := range s.replicas {
+		if err := r.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("could not close replicas: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	defaultRetryBaseDelay = 50 * time.Millisecond
+	defaultRetryMaxDelay  = 2 * time.Second
+)
+
+// RetryPolicy retries reads made outside a transaction and whole Transaction functions
+// when they fail with a transient error. Writes made outside Transaction are never retried
+// as they may have been applied before the error
+//
+//	eazydb.ClientOptions{Retry: eazydb.RetryPolicy{MaxAttempts: 3}}
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, 0 or 1 turns retries off
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry and doubles for each retry after,
+	// up to MaxDelay. A random jitter of up to half the delay is taken off. Defaults to 50ms and 2s
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Retryable decides which errors are retried, DefaultRetryable is used when nil
+	Retryable func(err error) bool
+}
+
+// DefaultRetryable retries serialization failures, deadlocks, dropped connections and
+// servers that are shutting down or starting up, as happens during a failover
+func DefaultRetryable(err error) bool {
+	if errors.Is(err, ErrConflict) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		// admin_shutdown, crash_shutdown, cannot_connect_now
+		case "57P01", "57P02", "57P03":
+			return true
+		}
+		// connection exceptions
+		return pqErr.Code.Class() == "08" || pqErrors[pqErr.Code] == ErrConflict
+	}
+	return false
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return DefaultRetryable(err)
+}
+
+// backoff returns how long to wait after the given attempt failed
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base, max := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	if max <= 0 {
+		max = defaultRetryMaxDelay
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay - time.Duration(rand.Int64N(int64(delay/2)+1))
+}
+
+// retry calls fn until it succeeds, fails with an error the policy doesn't retry, or runs
+// out of attempts. It returns the number of attempts made
+func retry(ctx context.Context, p RetryPolicy, log Logger, what string, fn func(attempt int) error) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= p.attempts() || !p.retryable(err) {
+			return attempt, err
+		}
+
+		wait := p.backoff(attempt)
+		log.Warn(what+" failed, retrying", "attempt", attempt, "attempts", p.attempts(), "wait", wait, "error", err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		case <-timer.C:
+		}
+	}
+}
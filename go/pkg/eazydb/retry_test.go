@@ -0,0 +1,160 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestDefaultRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "40001"}, true},
+		{&pq.Error{Code: "40P01"}, true},
+		{&pq.Error{Code: "57P01"}, true},
+		{&pq.Error{Code: "08006"}, true},
+		{fmt.Errorf("reading: %w", io.ErrUnexpectedEOF), true},
+		{driver.ErrBadConn, true},
+		{&pq.Error{Code: "23505"}, false},
+		{errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := DefaultRetryable(tt.err); got != tt.want {
+			t.Errorf("DefaultRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	p := RetryPolicy{Retryable: func(error) bool { return true }}
+	if p.retryable(context.Canceled) || p.retryable(fmt.Errorf("query: %w", context.DeadlineExceeded)) {
+		t.Errorf("a cancelled context should never be retried")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 10 * time.Millisecond},
+		{2, 20 * time.Millisecond},
+		{3, 40 * time.Millisecond},
+		{4, 50 * time.Millisecond},
+		{10, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if got := p.backoff(tt.attempt); got < tt.max/2 || got > tt.max {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.max/2, tt.max)
+			}
+		}
+	}
+}
+
+// failFirst answers the first n statements that aren't BEGIN or COMMIT with err
+func failFirst(n int32, err error) func(string) fakeResult {
+	var calls atomic.Int32
+	return func(query string) fakeResult {
+		if calls.Add(1) <= n {
+			return fakeResult{err: err}
+		}
+		if query[0] == 'S' {
+			return fakeResult{columns: []string{"name"}, rows: [][]driver.Value{{"Mat"}}}
+		}
+		return defaultResult(query)
+	}
+}
+
+func TestRetryReads(t *testing.T) {
+	c, fake := newTestClient(t)
+	c.retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	fake.respond = failFirst(2, &pq.Error{Code: "40001"})
+
+	var users []upsertUser
+	metadata, err := c.Table("users").Get(upsertUser{}).Exec(&users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Attempts != 3 || len(users) != 1 {
+		t.Errorf("got %d attempts and %d users, want 3 and 1", metadata.Attempts, len(users))
+	}
+
+	fake.respond = failFirst(3, &pq.Error{Code: "40001"})
+	if _, err := c.Table("users").Get(upsertUser{}).Exec(&users); !errors.Is(err, ErrConflict) {
+		t.Errorf("got error %v after running out of attempts", err)
+	}
+
+	fake.respond = failFirst(1, &pq.Error{Code: "23505"})
+	metadata, err = c.Table("users").Get(upsertUser{}).Exec(&users)
+	if !errors.Is(err, ErrDuplicate) {
+		t.Errorf("got error %v, want the first error back", err)
+	}
+	if metadata != nil && metadata.Attempts != 1 {
+		t.Errorf("got %d attempts, errors that aren't transient should not be retried", metadata.Attempts)
+	}
+}
+
+func TestRetrySkipsWrites(t *testing.T) {
+	c, fake := newTestClient(t)
+	c.retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	fake.respond = failFirst(1, &pq.Error{Code: "40001"})
+
+	if _, err := c.Table("users").Add(upsertUser{Name: "Mat"}).Exec(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("got error %v", err)
+	}
+	if got := fake.queries(); len(got) != 1 {
+		t.Errorf("a write outside a transaction was retried: %q", got)
+	}
+}
+
+func TestRetryTransaction(t *testing.T) {
+	c, fake := newTestClient(t)
+	c.retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	fake.respond = failFirst(2, &pq.Error{Code: "40P01"})
+
+	var attempts []int
+	err := c.Transaction(context.Background(), func(tx *Tx) error {
+		attempts = append(attempts, tx.Attempt())
+		_, err := tx.Table("users").Add(upsertUser{Name: "Mat"}).Exec()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(attempts) != "[1 2 3]" {
+		t.Errorf("got attempts %v", attempts)
+	}
+	want := []string{"BEGIN", "ROLLBACK", "BEGIN", "ROLLBACK", "BEGIN", "COMMIT"}
+	got := fake.statements()
+	var txStmts []string
+	for _, stmt := range got {
+		if stmt == "BEGIN" || stmt == "COMMIT" || stmt == "ROLLBACK" {
+			txStmts = append(txStmts, stmt)
+		}
+	}
+	if fmt.Sprint(txStmts) != fmt.Sprint(want) {
+		t.Errorf("got  %q\nwant %q", txStmts, want)
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := retry(ctx, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}, initLogger(nil, false), "test", func(int) error {
+		calls++
+		cancel()
+		return &pq.Error{Code: "40001"}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("got error %v after %d calls, want one call", err, calls)
+	}
+}
@@ -0,0 +1,145 @@
+package eazydb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// marks whether an upserted row was inserted or updated, see countsUpserts
+const upsertInsertedColumn = "eazydb_inserted"
+
+// Returning reads back the given columns from rows that were added, updated or deleted.
+// The values are written into the structs passed to Add or Update (by position),
+// or into the target passed to Exec instead.
+func (q *Query) Returning(columns ...string) *Query {
+	if len(columns) == 0 {
+		q.err = fmt.Errorf("Returning requires at least one column")
+		return q
+	}
+	q.returning = columns
+	return q
+}
+
+func (q *Query) hasReturning() bool {
+	return len(q.returning) > 0 || q.countsUpserts()
+}
+
+// Produces a clause like below
+//
+//	RETURNING id, created_at
+func (q *Query) constructReturningClause() (string, error) {
+	if !q.hasReturning() {
+		return "", nil
+	}
+
+	columns := append([]string{}, q.returning...)
+	if q.countsUpserts() {
+		// xmax is only set on rows that already existed, so it tells inserts and updates apart
+		columns = append(columns, fmt.Sprintf("(xmax = 0) AS %s", upsertInsertedColumn))
+	}
+	return " RETURNING " + strings.Join(columns, ", "), nil
+}
+
+func (q *Query) handleReturning(query statement, target interface{}) (*Metadata, error) {
+	metadata, data, err := q.queryReturning(query)
+	if err != nil {
+		return nil, err
+	}
+	return metadata, q.readReturned(data, target)
+}
+
+func (q *Query) queryReturning(query statement) (*Metadata, []map[string]interface{}, error) {
+	var metadata *Metadata = &Metadata{}
+
+	metadata.Query = query.sql
+	metadata.Args = query.values()
+	now := time.Now()
+	rows, err := q.conn().QueryContext(q.ctx, metadata.Query, metadata.Args...)
+	if err != nil {
+		q.logQuery(query, time.Since(now), 0, err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	data, err := q.scanRows(rows)
+	metadata.Duration = time.Since(now)
+	if err != nil {
+		q.logQuery(query, metadata.Duration, 0, err)
+		return nil, nil, err
+	}
+
+	metadata.RowsAffected = len(data)
+	metadata.RowsReturned = len(data)
+	q.logQuery(query, metadata.Duration, metadata.RowsReturned, nil)
+	for _, row := range data {
+		if _, ok := row[upsertInsertedColumn]; !ok {
+			continue
+		}
+		if inserted, _ := row[upsertInsertedColumn].(bool); inserted {
+			metadata.RowsInserted++
+		} else {
+			metadata.RowsUpdated++
+		}
+		delete(row, upsertInsertedColumn)
+	}
+	if q.op == dbtypes.INSERT && !q.countsUpserts() {
+		metadata.RowsInserted = metadata.RowsAffected
+	}
+
+	return metadata, data, nil
+}
+
+// readReturned writes the returned rows into target, or back into q.fields if no target is given
+func (q *Query) readReturned(data []map[string]interface{}, target interface{}) error {
+	if len(q.returning) == 0 {
+		return nil
+	}
+
+	if target != nil {
+		return unmarshalRows(data, target)
+	}
+	return q.writeBack(data)
+}
+
+// writeBack copies each returned row into the struct at the same position in q.fields
+func (q *Query) writeBack(data []map[string]interface{}) error {
+	v := reflect.ValueOf(q.fields)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if len(data) > v.Len() {
+			return fmt.Errorf("%v rows were returned but only %v were passed, pass a target to Exec instead", len(data), v.Len())
+		}
+		for i, row := range data {
+			if err := unmarshalRow(row, v.Index(i).Addr().Interface()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if !v.CanAddr() {
+			return fmt.Errorf("Returning needs a pointer to write into, pass &%v or a target to Exec", v.Type())
+		}
+		if len(data) > 0 {
+			return unmarshalRow(data[0], v.Addr().Interface())
+		}
+	default:
+		return fmt.Errorf("Returning needs a target passed to Exec for %v", q.op)
+	}
+	return nil
+}
+
+func unmarshalRow(row map[string]interface{}, obj interface{}) error {
+	bytes, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, obj)
+}
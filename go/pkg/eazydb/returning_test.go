@@ -0,0 +1,73 @@
+package eazydb
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type returningUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestReturningWritesBackIntoRows(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(string) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(7)}, {int64(8)}}}
+	}
+
+	users := []returningUser{{Name: "Mat"}, {Name: "Ann"}}
+	metadata, err := c.Table("users").Add(users).Returning("id").Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "INSERT INTO users (name) VALUES ($1), ($2) RETURNING id;"; metadata.Query != want {
+		t.Errorf("got  %s\nwant %s", metadata.Query, want)
+	}
+	if users[0].ID != 7 || users[1].ID != 8 {
+		t.Errorf("ids were not written back, got %+v", users)
+	}
+	if metadata.RowsInserted != 2 || metadata.RowsReturned != 2 {
+		t.Errorf("got %v inserted and %v returned, want 2 and 2", metadata.RowsInserted, metadata.RowsReturned)
+	}
+}
+
+func TestReturningIntoTarget(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(string) fakeResult {
+		return fakeResult{columns: []string{"id", "name"}, rows: [][]driver.Value{{int64(3), "Mat"}}}
+	}
+
+	var deleted []returningUser
+	metadata, err := c.Table("users").Delete().Where(*Int("age").Equals(40)).Returning("id", "name").Exec(&deleted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "DELETE FROM users  WHERE age = $1 RETURNING id, name"; metadata.Query != want {
+		t.Errorf("got  %s\nwant %s", metadata.Query, want)
+	}
+	if len(deleted) != 1 || deleted[0].ID != 3 || deleted[0].Name != "Mat" {
+		t.Errorf("got %+v", deleted)
+	}
+}
+
+func TestReturningNeedsAddressableRow(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(string) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+
+	_, err := c.Table("users").Add(returningUser{Name: "Mat"}).Returning("id").Exec()
+	if err == nil || !strings.Contains(err.Error(), "needs a pointer") {
+		t.Fatalf("got error %v, want a pointer to be asked for", err)
+	}
+
+	user := returningUser{Name: "Mat"}
+	if _, err := c.Table("users").Add(&user).Returning("id").Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 1 {
+		t.Errorf("got id %v, want 1", user.ID)
+	}
+}
@@ -0,0 +1,106 @@
+package eazydb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// marks where a guarded statement started inside a transaction the caller owns
+const guardSavepoint = "eazydb_guard"
+
+// AllowAll lets an Update or Delete without conditions run against every row in the table.
+// Without it safe mode refuses the query, unless safe mode is disabled in ClientOptions
+func (q *Query) AllowAll() *Query {
+	q.unsafe = true
+	return q
+}
+
+// MaxAffected rolls back an Update or Delete that changes more than max rows and returns an error.
+// It overrides ClientOptions.MaxRowsAffected, 0 turns the guard off
+func (q *Query) MaxAffected(max int) *Query {
+	if max < 0 {
+		q.err = fmt.Errorf("max rows affected cannot be negative, got %v", max)
+		return q
+	}
+	q.maxAffected = max
+	return q
+}
+
+// checkBounded refuses an Update or Delete that would touch every row of the table
+func (q *Query) checkBounded() error {
+	if q.op != dbtypes.UPDATE && q.op != dbtypes.DELETE {
+		return nil
+	}
+	if q.unsafe || len(q.conditions) > 0 {
+		return nil
+	}
+	return fmt.Errorf("refusing to %s every row in %s without conditions, add Where or call AllowAll", q.op, q.name)
+}
+
+func (q *Query) guarded() bool {
+	return q.maxAffected > 0 && (q.op == dbtypes.UPDATE || q.op == dbtypes.DELETE)
+}
+
+// handleGuarded runs the statement in a transaction, or behind a savepoint if the query is
+// already in one, and rolls it back if it affected more rows than allowed
+func (q *Query) handleGuarded(query statement, target interface{}) (*Metadata, error) {
+	worker := *q
+	var tx *sql.Tx
+	if q.tx == nil {
+		var err error
+		tx, err = q.db.BeginTx(q.ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		worker.tx = tx
+	} else if _, err := q.tx.ExecContext(q.ctx, "SAVEPOINT "+guardSavepoint); err != nil {
+		return nil, err
+	}
+
+	var metadata *Metadata
+	var data []map[string]interface{}
+	var err error
+	if q.hasReturning() {
+		metadata, data, err = worker.queryReturning(query)
+	} else {
+		metadata, err = worker.handleExec(query)
+	}
+	if err != nil {
+		q.rollbackGuard()
+		return nil, err
+	}
+
+	if metadata.RowsAffected > q.maxAffected {
+		if err := q.rollbackGuard(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s on %s affected %v rows which is more than the limit of %v, it was rolled back",
+			q.op, q.name, metadata.RowsAffected, q.maxAffected)
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	} else if _, err := q.tx.ExecContext(q.ctx, "RELEASE SAVEPOINT "+guardSavepoint); err != nil {
+		return nil, err
+	}
+
+	if q.hasReturning() {
+		return metadata, q.readReturned(data, target)
+	}
+	return metadata, nil
+}
+
+// rollbackGuard undoes the guarded statement when it ran inside the caller's transaction.
+// A transaction owned by handleGuarded is rolled back by its deferred Rollback
+func (q *Query) rollbackGuard() error {
+	if q.tx == nil {
+		return nil
+	}
+	_, err := q.tx.ExecContext(q.ctx, "ROLLBACK TO SAVEPOINT "+guardSavepoint)
+	return err
+}
@@ -0,0 +1,76 @@
+package eazydb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSafeModeRefusesUnboundedWrites(t *testing.T) {
+	c, fake := newTestClient(t)
+
+	for _, q := range []*Query{
+		c.Table("users").Update(updateUser{Name: "Mat"}),
+		c.Table("users").Delete(),
+	} {
+		_, err := q.Exec()
+		if err == nil || !strings.Contains(err.Error(), "without conditions, add Where or call AllowAll") {
+			t.Errorf("got error %v", err)
+		}
+	}
+	if got := fake.statements(); len(got) != 0 {
+		t.Errorf("refused queries reached the database: %q", got)
+	}
+
+	if _, err := c.Table("users").Delete().AllowAll().Exec(); err != nil {
+		t.Errorf("AllowAll was refused: %v", err)
+	}
+	c.unsafe = true
+	if _, err := c.Table("users").Delete().Exec(); err != nil {
+		t.Errorf("DisableSafeMode was refused: %v", err)
+	}
+}
+
+func TestMaxAffectedRollsBack(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(query string) fakeResult { return fakeResult{affected: 5} }
+
+	_, err := c.Table("users").Update(updateUser{Name: "Mat"}).Where(*Int("age").GreaterThan(1)).MaxAffected(3).Exec()
+	if err == nil || !strings.Contains(err.Error(), "affected 5 rows which is more than the limit of 3, it was rolled back") {
+		t.Fatalf("got error %v", err)
+	}
+	want := []string{"BEGIN", "UPDATE users SET name = $1 WHERE age > $2 [Mat 1]", "ROLLBACK"}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestMaxAffectedInsideTransaction(t *testing.T) {
+	c, fake := newTestClient(t)
+	c.maxAffected = 3
+	fake.respond = func(query string) fakeResult {
+		if strings.HasPrefix(query, "DELETE") {
+			return fakeResult{affected: 2}
+		}
+		return defaultResult(query)
+	}
+
+	err := c.Transaction(context.Background(), func(tx *Tx) error {
+		_, err := tx.Table("users").Delete().Where(*Int("age").GreaterThan(1)).Exec()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"BEGIN",
+		"SAVEPOINT eazydb_guard",
+		"DELETE FROM users  WHERE age > $1 [1]",
+		"RELEASE SAVEPOINT eazydb_guard",
+		"COMMIT",
+	}
+	if got := fake.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package eazydb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// softDeleteColumn holds the time a row was soft deleted, it is NULL for live rows
+const softDeleteColumn = "deleted_at"
+
+type deletedScope int
+
+const (
+	excludeDeleted deletedScope = iota
+	withDeleted
+	onlyDeleted
+)
+
+// tableSet remembers the tables that use soft deletes
+type tableSet struct {
+	mu     sync.RWMutex
+	tables map[string]bool
+}
+
+func newTableSet() *tableSet {
+	return &tableSet{tables: make(map[string]bool)}
+}
+
+func (s *tableSet) add(table string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[table] = true
+}
+
+func (s *tableSet) remove(table string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tables, table)
+}
+
+func (s *tableSet) rename(table string, newName string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tables[table] {
+		s.tables[newName] = true
+		delete(s.tables, table)
+	}
+}
+
+func (s *tableSet) has(table string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables[table]
+}
+
+// SoftDelete marks tables with a deleted_at column as soft deleted. Delete then sets deleted_at
+// instead of removing the row, and Get and Update skip deleted rows. Tables created with
+// NewTable from a struct with a deleted_at field are marked automatically
+func (c *Client) SoftDelete(tables ...string) {
+	for _, table := range tables {
+		c.softDeletes.add(table)
+	}
+}
+
+func (q *Query) isSoftDelete() bool {
+	return q.softDeletes.has(q.name)
+}
+
+// WithDeleted includes soft deleted rows
+func (q *Query) WithDeleted() *Query {
+	if !q.isSoftDelete() {
+		q.err = fmt.Errorf("WithDeleted can only be used on soft delete tables, %s is not one", q.name)
+		return q
+	}
+	q.scope = withDeleted
+	return q
+}
+
+// OnlyDeleted only matches soft deleted rows
+func (q *Query) OnlyDeleted() *Query {
+	if !q.isSoftDelete() {
+		q.err = fmt.Errorf("OnlyDeleted can only be used on soft delete tables, %s is not one", q.name)
+		return q
+	}
+	q.scope = onlyDeleted
+	return q
+}
+
+// HardDelete removes the rows rather than setting deleted_at. Rows that were already soft
+// deleted are included unless OnlyDeleted is used
+//
+//	table.Delete().HardDelete().OnlyDeleted().Where(...)
+func (q *Query) HardDelete() *Query {
+	if q.op != dbtypes.DELETE {
+		q.err = fmt.Errorf("HardDelete can only be used with Delete, table operation is %v", q.op)
+		return q
+	}
+	q.hardDelete = true
+	return q
+}
+
+// Restore clears deleted_at on the matching soft deleted rows
+//
+//	UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL
+func (q *Query) Restore() *Query {
+	if q.op != "" {
+		q.err = fmt.Errorf("table operation already set to %v and so cannot be set to restore", q.op)
+	}
+	if !q.isSoftDelete() {
+		q.err = fmt.Errorf("Restore can only be used on soft delete tables, %s is not one", q.name)
+	}
+	q.op = dbtypes.UPDATE
+	q.sets = append(q.sets, field{Name: softDeleteColumn, Val: nil})
+	q.scope = onlyDeleted
+	return q
+}
+
+// softDeleting reports whether Delete should set deleted_at rather than remove rows
+func (q *Query) softDeleting() bool {
+	return q.op == dbtypes.DELETE && !q.hardDelete && q.isSoftDelete()
+}
+
+// scopeConditions returns the deleted_at condition added to the query's own conditions
+func (q *Query) scopeConditions() []Condition {
+	if !q.isSoftDelete() {
+		return nil
+	}
+	if q.op == dbtypes.DELETE && q.hardDelete && q.scope == excludeDeleted {
+		return nil
+	}
+
+	switch q.scope {
+	case excludeDeleted:
+		return []Condition{sqlCondition(fmt.Sprintf("%s IS NULL", softDeleteColumn))}
+	case onlyDeleted:
+		return []Condition{sqlCondition(fmt.Sprintf("%s IS NOT NULL", softDeleteColumn))}
+	}
+	return nil
+}
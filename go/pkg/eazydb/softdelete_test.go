@@ -0,0 +1,83 @@
+package eazydb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSoftDeleteScoping(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.SoftDelete("users")
+	where := *Int("id").Equals(1)
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  string
+		args  []interface{}
+	}{
+		{"get skips deleted", c.Table("users").Get(updateUser{}).Where(where), "SELECT name, age, active, nick FROM users WHERE id = $1 AND deleted_at IS NULL", []interface{}{1}},
+		{"with deleted", c.Table("users").Get(updateUser{}).WithDeleted().Where(where), "SELECT name, age, active, nick FROM users WHERE id = $1", []interface{}{1}},
+		{"only deleted", c.Table("users").Get(updateUser{}).OnlyDeleted().Where(where), "SELECT name, age, active, nick FROM users WHERE id = $1 AND deleted_at IS NOT NULL", []interface{}{1}},
+		{"update skips deleted", c.Table("users").Update(updateUser{Name: "Mat"}).Where(where), "UPDATE users SET name = $1 WHERE id = $2 AND deleted_at IS NULL", []interface{}{"Mat", 1}},
+		{"delete sets deleted_at", c.Table("users").Delete().Where(where), "UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL", []interface{}{1}},
+		{"hard delete", c.Table("users").Delete().HardDelete().Where(where), "DELETE FROM users  WHERE id = $1", []interface{}{1}},
+		{"hard delete only deleted", c.Table("users").Delete().HardDelete().OnlyDeleted().Where(where), "DELETE FROM users  WHERE id = $1 AND deleted_at IS NOT NULL", []interface{}{1}},
+		{"restore", c.Table("users").Restore().Where(where), "UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL", []interface{}{1}},
+		{"other tables are untouched", c.Table("orders").Delete().Where(where), "DELETE FROM orders  WHERE id = $1", []interface{}{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.query.Dry().Exec()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Query != tt.want {
+				t.Errorf("got  %s\nwant %s", metadata.Query, tt.want)
+			}
+			if !reflect.DeepEqual(metadata.Args, tt.args) {
+				t.Errorf("got args %v, want %v", metadata.Args, tt.args)
+			}
+		})
+	}
+}
+
+func TestNewTableMarksSoftDeletes(t *testing.T) {
+	c, _ := newTestClient(t)
+	_, err := c.NewTable("users").Fields(struct {
+		Name      string     `json:"name"`
+		DeletedAt *time.Time `json:"deleted_at"`
+	}{}).Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.softDeletes.has("users") {
+		t.Errorf("users was not marked as a soft delete table")
+	}
+}
+
+func TestSoftDeleteErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.SoftDelete("users")
+
+	tests := []struct {
+		name  string
+		query *Query
+		err   string
+	}{
+		{"with deleted on plain table", c.Table("orders").Get(updateUser{}).WithDeleted(), "WithDeleted can only be used on soft delete tables"},
+		{"only deleted on plain table", c.Table("orders").Get(updateUser{}).OnlyDeleted(), "OnlyDeleted can only be used on soft delete tables"},
+		{"restore on plain table", c.Table("orders").Restore(), "Restore can only be used on soft delete tables"},
+		{"hard delete on get", c.Table("users").Get(updateUser{}).HardDelete(), "HardDelete can only be used with Delete"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.query.Dry().Exec()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
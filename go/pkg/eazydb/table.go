@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
-	"github.com/sirupsen/logrus"
 )
 
 type TableInstance struct {
@@ -20,7 +19,15 @@ type TableInstance struct {
 	addNewFields bool
 	errIfExists  bool
 	err          error
-	log          *logrus.Logger
+	log          *queryLogger
+	keys         *keyRegistry
+	softDeletes  *tableSet
+	primaryKey   []string
+	uniques      [][]string
+	checks       []string
+	notNull      map[string]bool
+	defaults     map[string]string
+	foreignKeys  []ForeignKey
 }
 
 type TableKey struct {
@@ -29,10 +36,17 @@ type TableKey struct {
 }
 
 type Metadata struct {
-	Query        string
+	Query string
+	// Args are the values of the $n placeholders in Query. Each statement of a batched
+	// insert numbers its placeholders from $1, with its args following those of the one before
+	Args         []interface{}
 	Duration     time.Duration
 	RowsAffected int
 	RowsReturned int
+	RowsInserted int
+	RowsUpdated  int
+	Attempts     int
+	Batches      []Batch
 }
 
 func (c *Client) NewTable(name string) *TableInstance {
@@ -41,10 +55,12 @@ func (c *Client) NewTable(name string) *TableInstance {
 		err = errors.New("a table name is required")
 	}
 	return &TableInstance{
-		db:   c.DB,
-		name: name,
-		err:  err,
-		log:  c.log,
+		db:          c.DB,
+		name:        name,
+		err:         err,
+		log:         c.log,
+		keys:        c.keys,
+		softDeletes: c.softDeletes,
 	}
 }
 
@@ -77,43 +93,78 @@ func (t *TableInstance) Exec() (*Metadata, error) {
 		return nil, t.err
 	}
 
+	var metadata *Metadata = &Metadata{}
+
+	metadata.Query, t.err = t.constructQuery()
+	if t.err != nil {
+		return nil, fmt.Errorf("could not construct query: %v", t.err)
+	}
+	fields, err := t.constructFields()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.exec(metadata, "CREATE", metadata.Query); err != nil {
+		return nil, err
+	}
+
+	// CREATE TABLE IF NOT EXISTS leaves an existing table untouched, so bring it up to date with the struct
 	if t.addNewFields {
 		collumns, err := t.getColumns()
 		if err != nil {
-			return nil, t.err
+			return nil, err
 		}
 
-		t.addNewCollumns(collumns)
+		if err := t.addNewCollumns(metadata, collumns); err != nil {
+			return nil, fmt.Errorf("could not add new columns: %v", err)
+		}
 
+		if err := t.addNewForeignKeys(metadata, fields); err != nil {
+			return nil, fmt.Errorf("could not add new foreign keys: %v", err)
+		}
 	}
 
-	var metadata *Metadata = &Metadata{}
-
-	metadata.Query, t.err = t.constructQuery()
-	if t.err != nil {
-		return nil, fmt.Errorf("could not construct query: %v", t.err)
+	// indexes are created once every column they cover exists
+	for _, index := range t.constructIndexes(fields) {
+		if err := t.exec(metadata, "CREATE", index); err != nil {
+			return nil, fmt.Errorf("could not create index: %v", err)
+		}
+		metadata.Query += " " + index
 	}
-	t.log.Debugf("constructed query: %v", metadata.Query)
 
-	now := time.Now()
-	result, err := t.db.Exec(metadata.Query)
-	metadata.Duration = time.Since(now)
-	t.log.Debugf("query execution took %v", metadata.Duration)
-	if err != nil {
-		return nil, err
-	}
-	affected, err := result.RowsAffected()
-	if err == nil {
-		metadata.RowsAffected = int(affected)
+	t.keys.set(t.name, t.keyColumns(fields))
+	if hasField(fields, softDeleteColumn) {
+		t.softDeletes.add(t.name)
 	}
 
 	return metadata, nil
 
 }
 
-func (k *TableKey) constructQuery() string {
-	return fmt.Sprintf("%s %v PRIMARY KEY,", k.Name, k.Type)
+// exec runs a statement of the table and adds it to the metadata
+func (t *TableInstance) exec(metadata *Metadata, op string, stmt string) error {
+	now := time.Now()
+	result, err := t.db.Exec(stmt)
+	duration := time.Since(now)
+	metadata.Duration += duration
+	if err != nil {
+		t.log.query(queryEntry{table: t.name, op: op, query: stmt, duration: duration, err: err})
+		return err
+	}
+	var rows int
+	if affected, err := result.RowsAffected(); err == nil {
+		rows = int(affected)
+	}
+	metadata.RowsAffected += rows
+	t.log.query(queryEntry{table: t.name, op: op, query: stmt, duration: duration, rows: rows})
+	return nil
+}
 
+func (k *TableKey) constructQuery(inline bool) string {
+	if !inline {
+		return fmt.Sprintf("%s %v", k.Name, k.Type)
+	}
+	return fmt.Sprintf("%s %v PRIMARY KEY", k.Name, k.Type)
 }
 
 func (t *TableInstance) constructQuery() (string, error) {
@@ -123,34 +174,44 @@ func (t *TableInstance) constructQuery() (string, error) {
 	} else {
 		stmt += fmt.Sprintf(`CREATE TABLE %s (`, t.name)
 	}
-	stmt += t.key.constructQuery()
 	fields, err := t.constructFields()
 	if err != nil {
 		return "", err
 	}
-	for i, field := range fields {
-		last := false
-		if i == len(fields)-1 {
-			last = true
-		}
-		stmt = field.appendStatement(stmt, last)
+	if err := t.validateColumns(fields); err != nil {
+		return "", err
+	}
+
+	var defs []string
+	if t.key != nil {
+		defs = append(defs, t.key.constructQuery(t.inlineKey(t.keyColumns(fields))))
 	}
+	for _, field := range fields {
+		defs = append(defs, field.definition())
+	}
+	constraints, err := t.constructConstraints(fields)
+	if err != nil {
+		return "", err
+	}
+	defs = append(defs, constraints...)
+
+	stmt += strings.Join(defs, ", ")
+	stmt += ");"
 	return stmt, nil
 }
 
 type field struct {
-	Name    string
-	SQLType dbtypes.ValType
-	Val     interface{}
-}
-
-func (f *field) appendStatement(stmt string, last bool) string {
-	if !last {
-		stmt += fmt.Sprintf("%s %v,", f.Name, f.SQLType)
-	} else {
-		stmt += fmt.Sprintf("%s %v);", f.Name, f.SQLType)
-	}
-	return stmt
+	Name        string
+	SQLType     dbtypes.ValType
+	Val         interface{}
+	notNull     bool
+	unique      bool
+	primaryKey  bool
+	defaultVal  string
+	indexed     bool
+	uniqueIndex bool
+	index       string
+	references  *ForeignKey
 }
 
 func (t *TableInstance) constructFields() ([]field, error) {
@@ -159,24 +220,51 @@ func (t *TableInstance) constructFields() ([]field, error) {
 	reflected := reflect.TypeOf(t.fields)
 	reflectedValue := reflect.ValueOf(t.fields)
 
-	for i := 0; i < reflected.NumField(); i++ {
-		f := reflected.Field(i)
+	for _, f := range columnFields(reflected) {
 		name := f.Tag.Get("json")
-		if name != "" && (t.key.Name != name && t.key.Type == dbtypes.SERIAL) {
-			t.log.Debugf("extracted field %v from struct", name)
-			kind := f.Type.Kind()
-			parsed, err := dbtypes.ToSQL(kind)
+		if name != "" && !isRelation(f) && (t.key == nil || t.key.Name != name) {
+			t.log.Debug("extracted field from struct", "table", t.name, "field", name)
+			fieldType := f.Type
+			// pointers are nullable columns of the type they point at
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			parsed, err := dbtypes.ToSQLType(fieldType)
 			if err != nil {
 				return nil, fmt.Errorf("%v could not be parsed to sql: %v", name, err)
 			}
-
+			parsed = columnType(f, parsed)
+
+			opts := parseTag(f)
+			var references *ForeignKey
+			if opts.references != "" {
+				var err error
+				references, err = parseReference(opts.references)
+				if err != nil {
+					return nil, fmt.Errorf("%v has an invalid db tag: %v", name, err)
+				}
+				references.OnDelete = opts.onDelete
+				references.OnUpdate = opts.onUpdate
+			}
+			defaultVal := opts.defaultVal
+			if val, ok := t.defaults[name]; ok {
+				defaultVal = val
+			}
 			fields = append(fields, field{
-				Name:    name,
-				SQLType: parsed,
-				Val:     reflectedValue.Field(i).Interface(),
+				Name:        name,
+				SQLType:     parsed,
+				Val:         reflectedValue.FieldByIndex(f.Index).Interface(),
+				notNull:     opts.notNull || t.notNull[name],
+				unique:      opts.unique,
+				primaryKey:  opts.primaryKey,
+				defaultVal:  defaultVal,
+				indexed:     opts.indexed,
+				uniqueIndex: opts.uniqueIndex,
+				index:       opts.index,
+				references:  references,
 			})
 		} else {
-			t.log.Debugf("field %v does not have a json tag or is the primary key of type SERIAL. ignoring", f.Name)
+			t.log.Debug("field does not have a json tag or is declared with Key, ignoring", "table", t.name, "field", f.Name)
 		}
 
 	}
@@ -187,7 +275,7 @@ func (t *TableInstance) constructFields() ([]field, error) {
 	return fields, nil
 }
 
-func (t *TableInstance) addNewCollumns(collumns []string) error {
+func (t *TableInstance) addNewCollumns(metadata *Metadata, collumns []string) error {
 	fields, err := t.constructFields()
 	if err != nil {
 		return err
@@ -201,15 +289,17 @@ func (t *TableInstance) addNewCollumns(collumns []string) error {
 
 	var adds []string
 	for _, col := range newCols {
-		adds = append(adds, fmt.Sprintf(" ADD COLUMN %s %s", col.Name, col.SQLType))
+		adds = append(adds, fmt.Sprintf(" ADD COLUMN %s", col.definition()))
 	}
 
 	stmt = fmt.Sprintf("%s %s", stmt, strings.Join(adds, ","))
 	stmt += ";"
 
-	t.log.Debugf("adding collumns to table %s with query: %s", t.name, stmt)
-	_, err = t.db.Exec(stmt)
-	return err
+	if err := t.exec(metadata, "ALTER", stmt); err != nil {
+		return err
+	}
+	metadata.Query += " " + stmt
+	return nil
 }
 
 func getNewFieldsNotInColumns(fields []field, columns []string) []field {
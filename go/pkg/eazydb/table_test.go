@@ -0,0 +1,152 @@
+package eazydb
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+type order struct {
+	TenantID int    `json:"tenant_id"`
+	ID       int    `json:"id"`
+	Email    string `json:"email" db:",notnull,unique"`
+	Age      int    `json:"age" db:",default=18"`
+	Note     string `json:"note"`
+}
+
+type taggedKey struct {
+	TenantID int    `json:"tenant_id" db:",pk"`
+	ID       int    `json:"id" db:",pk"`
+	Name     string `json:"name"`
+}
+
+// createTable runs NewTable and returns the statements sent to the database
+func createTable(t *testing.T, build func(c *Client) *TableInstance) []string {
+	t.Helper()
+	c, fake := newTestClient(t)
+	if _, err := build(c).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	return fake.queries()
+}
+
+func TestNewTableConstraints(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(c *Client) *TableInstance
+		want  string
+	}{
+		{
+			name: "composite key and table constraints",
+			build: func(c *Client) *TableInstance {
+				return c.NewTable("orders").Fields(order{}).PrimaryKey("tenant_id", "id").
+					Unique("tenant_id", "note").Check("age >= 0").NotNull("note").Default("note", "none")
+			},
+			want: "CREATE TABLE IF NOT EXISTS orders (tenant_id INT, id INT, email TEXT NOT NULL UNIQUE, age INT DEFAULT 18, note TEXT NOT NULL DEFAULT 'none'," +
+				" PRIMARY KEY (tenant_id, id), UNIQUE (tenant_id, note), CHECK (age >= 0));",
+		},
+		{
+			name: "key tags",
+			build: func(c *Client) *TableInstance {
+				return c.NewTable("orders").Fields(taggedKey{}).ErrorIfExists()
+			},
+			want: "CREATE TABLE orders (tenant_id INT, id INT, name TEXT, PRIMARY KEY (tenant_id, id));",
+		},
+		{
+			name: "single key is inline",
+			build: func(c *Client) *TableInstance {
+				return c.NewTable("orders").Fields(struct {
+					Name string `json:"name"`
+				}{}).Key("id", dbtypes.SERIAL)
+			},
+			want: "CREATE TABLE IF NOT EXISTS orders (id SERIAL PRIMARY KEY, name TEXT);",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := createTable(t, tt.build)
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTableRemembersKeys(t *testing.T) {
+	c, _ := newTestClient(t)
+	if _, err := c.NewTable("orders").Fields(order{}).PrimaryKey("tenant_id", "id").Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if keys := c.keys.get("orders"); strings.Join(keys, ",") != "tenant_id,id" {
+		t.Errorf("got keys %v", keys)
+	}
+}
+
+func TestNewTableErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	tests := []struct {
+		name  string
+		table *TableInstance
+		err   string
+	}{
+		{"no name", c.NewTable("").Fields(order{}), "a table name is required"},
+		{"empty key", c.NewTable("orders").Fields(order{}).PrimaryKey(), "PrimaryKey requires at least one column"},
+		{"unknown column", c.NewTable("orders").Fields(order{}).Unique("phone"), "column phone is used in a constraint"},
+		{"untagged struct", c.NewTable("orders").Fields(struct{ Name string }{}), "no valid fields were found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.table.Exec()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+type migratedUser struct {
+	Email  string `json:"email"`
+	Phone  string `json:"phone" db:",index"`
+	TeamID int    `json:"team_id" db:",references=teams(id),ondelete=cascade"`
+}
+
+func TestAddNewFieldsMigratesExistingTable(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(query string) fakeResult {
+		switch {
+		case strings.Contains(query, "information_schema.columns"):
+			return fakeResult{columns: []string{"column_name"}, rows: [][]driver.Value{{"email"}}}
+		case strings.Contains(query, "pg_constraint"):
+			return fakeResult{columns: []string{"conname", "columns", "relname", "ref_columns", "confdeltype", "confupdtype"}}
+		}
+		return defaultResult(query)
+	}
+
+	metadata, err := c.NewTable("users").Fields(migratedUser{}).AddNewFields().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, stmt := range fake.queries() {
+		if !strings.Contains(stmt, "information_schema") && !strings.Contains(stmt, "pg_constraint") {
+			got = append(got, stmt)
+		}
+	}
+	want := []string{
+		"CREATE TABLE IF NOT EXISTS users (email TEXT, phone TEXT, team_id INT, CONSTRAINT users_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE);",
+		"ALTER TABLE users  ADD COLUMN phone TEXT, ADD COLUMN team_id INT;",
+		"ALTER TABLE users ADD CONSTRAINT users_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE;",
+		"CREATE INDEX IF NOT EXISTS users_phone_idx ON users (phone);",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	if !strings.HasSuffix(metadata.Query, "CREATE INDEX IF NOT EXISTS users_phone_idx ON users (phone);") {
+		t.Errorf("index is missing from the query %q", metadata.Query)
+	}
+}
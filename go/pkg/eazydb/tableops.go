@@ -0,0 +1,272 @@
+package eazydb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type tableOpKind string
+
+const (
+	dropTable     tableOpKind = "drop"
+	truncateTable tableOpKind = "truncate"
+	renameTable   tableOpKind = "rename"
+	cloneTable    tableOpKind = "clone"
+)
+
+// TableOperation drops, truncates, renames or clones a table
+type TableOperation struct {
+	db              *sql.DB
+	op              tableOpKind
+	name            string
+	target          string
+	ifExists        bool
+	cascade         bool
+	restartIdentity bool
+	withData        bool
+	dryrun          bool
+	err             error
+	log             *queryLogger
+	keys            *keyRegistry
+	softDeletes     *tableSet
+}
+
+func (c *Client) newTableOperation(op tableOpKind, name string, target string) *TableOperation {
+	var err error = nil
+	if name == "" {
+		err = errors.New("a table name is required")
+	}
+	if (op == renameTable || op == cloneTable) && target == "" {
+		err = errors.New("a new table name is required")
+	}
+	return &TableOperation{
+		db:          c.DB,
+		op:          op,
+		name:        name,
+		target:      target,
+		err:         err,
+		log:         c.log,
+		keys:        c.keys,
+		softDeletes: c.softDeletes,
+	}
+}
+
+// DROP TABLE users;
+func (c *Client) DropTable(name string) *TableOperation {
+	return c.newTableOperation(dropTable, name, "")
+}
+
+// TRUNCATE TABLE users;
+func (c *Client) TruncateTable(name string) *TableOperation {
+	return c.newTableOperation(truncateTable, name, "")
+}
+
+// ALTER TABLE users RENAME TO customers;
+func (c *Client) RenameTable(name string, newName string) *TableOperation {
+	return c.newTableOperation(renameTable, name, newName)
+}
+
+// CloneTable creates a table with the same columns, defaults, constraints, foreign keys and
+// indexes. SERIAL columns get sequences of their own. Rows are only copied with WithData
+func (c *Client) CloneTable(src string, dst string) *TableOperation {
+	return c.newTableOperation(cloneTable, src, dst)
+}
+
+// IfExists skips the drop or rename if the table does not exist
+func (t *TableOperation) IfExists() *TableOperation {
+	if t.op != dropTable && t.op != renameTable {
+		t.err = fmt.Errorf("IfExists can only be used with DropTable or RenameTable")
+	}
+	t.ifExists = true
+	return t
+}
+
+// Cascade also drops or truncates the tables that reference this one
+func (t *TableOperation) Cascade() *TableOperation {
+	if t.op != dropTable && t.op != truncateTable {
+		t.err = fmt.Errorf("Cascade can only be used with DropTable or TruncateTable")
+	}
+	t.cascade = true
+	return t
+}
+
+// RestartIdentity resets the sequences of SERIAL columns when truncating
+func (t *TableOperation) RestartIdentity() *TableOperation {
+	if t.op != truncateTable {
+		t.err = fmt.Errorf("RestartIdentity can only be used with TruncateTable")
+	}
+	t.restartIdentity = true
+	return t
+}
+
+// WithData copies the rows of the source table into the clone
+func (t *TableOperation) WithData() *TableOperation {
+	if t.op != cloneTable {
+		t.err = fmt.Errorf("WithData can only be used with CloneTable")
+	}
+	t.withData = true
+	return t
+}
+
+func (t *TableOperation) Dry() *TableOperation {
+	t.dryrun = true
+	return t
+}
+
+func (t *TableOperation) Exec() (*Metadata, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	var metadata *Metadata = &Metadata{}
+	stmts, err := t.constructQueries()
+	if err != nil {
+		return nil, fmt.Errorf("could not construct query: %v", err)
+	}
+	for i, stmt := range stmts {
+		if i > 0 {
+			metadata.Query += " "
+		}
+		metadata.Query += stmt
+	}
+	if t.dryrun {
+		return metadata, nil
+	}
+
+	now := time.Now()
+	// a clone is made of several statements, so none are kept if one fails
+	tx, err := t.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range stmts {
+		result, err := tx.Exec(stmt)
+		if err != nil {
+			t.log.query(queryEntry{table: t.name, op: string(t.op), query: stmt, duration: time.Since(now), err: err})
+			return nil, err
+		}
+		if affected, err := result.RowsAffected(); err == nil {
+			metadata.RowsAffected += int(affected)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	metadata.Duration = time.Since(now)
+	t.log.query(queryEntry{table: t.name, op: string(t.op), query: metadata.Query, duration: metadata.Duration, rows: metadata.RowsAffected})
+
+	switch t.op {
+	case dropTable:
+		t.keys.remove(t.name)
+		t.softDeletes.remove(t.name)
+	case renameTable:
+		t.keys.rename(t.name, t.target)
+		t.softDeletes.rename(t.name, t.target)
+	}
+	return metadata, nil
+}
+
+func (t *TableOperation) constructQueries() ([]string, error) {
+	switch t.op {
+	case dropTable:
+		stmt := "DROP TABLE"
+		if t.ifExists {
+			stmt += " IF EXISTS"
+		}
+		stmt += " " + t.name
+		if t.cascade {
+			stmt += " CASCADE"
+		}
+		return []string{stmt + ";"}, nil
+	case truncateTable:
+		stmt := "TRUNCATE TABLE " + t.name
+		if t.restartIdentity {
+			stmt += " RESTART IDENTITY"
+		}
+		if t.cascade {
+			stmt += " CASCADE"
+		}
+		return []string{stmt + ";"}, nil
+	case renameTable:
+		stmt := "ALTER TABLE"
+		if t.ifExists {
+			stmt += " IF EXISTS"
+		}
+		return []string{fmt.Sprintf("%s %s RENAME TO %s;", stmt, t.name, t.target)}, nil
+	case cloneTable:
+		return t.constructCloneQueries()
+	}
+	return nil, nil
+}
+
+// LIKE ... INCLUDING ALL leaves out foreign keys, and SERIAL columns keep using the sequence
+// of the source table, so both are added after the table is created
+//
+//	CREATE TABLE users_backup (LIKE users INCLUDING ALL);
+//	CREATE SEQUENCE users_backup_id_seq OWNED BY users_backup.id;
+//	ALTER TABLE users_backup ALTER COLUMN id SET DEFAULT nextval('users_backup_id_seq');
+//	ALTER TABLE users_backup ADD CONSTRAINT users_backup_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id);
+//	INSERT INTO users_backup SELECT * FROM users;
+//	SELECT setval('users_backup_id_seq', COALESCE(MAX(id), 0) + 1, false) FROM users_backup;
+func (t *TableOperation) constructCloneQueries() ([]string, error) {
+	serials, err := serialColumns(t.db, t.name)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up the SERIAL columns of %s: %v", t.name, err)
+	}
+	fks, err := foreignKeys(t.db, t.name)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up the foreign keys of %s: %v", t.name, err)
+	}
+
+	stmts := []string{fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL);", t.target, t.name)}
+	for _, col := range serials {
+		seq := fmt.Sprintf("%s_%s_seq", t.target, col)
+		stmts = append(stmts,
+			fmt.Sprintf("CREATE SEQUENCE %s OWNED BY %s.%s;", seq, t.target, col),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT nextval('%s');", t.target, col, seq))
+	}
+	for _, fk := range fks {
+		fk.Name = fmt.Sprintf("%s_%s_fkey", t.target, strings.Join(fk.Columns, "_"))
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD %s;", t.target, fk.constructQuery()))
+	}
+	if t.withData {
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO %s SELECT * FROM %s;", t.target, t.name))
+		// carry on from the copied ids rather than reusing them
+		for _, col := range serials {
+			stmts = append(stmts, fmt.Sprintf("SELECT setval('%s_%s_seq', COALESCE(MAX(%s), 0) + 1, false) FROM %s;", t.target, col, col, t.target))
+		}
+	}
+	return stmts, nil
+}
+
+// serialColumns returns the columns of a table whose default comes from a sequence
+func serialColumns(db *sql.DB, table string) ([]string, error) {
+	var columns []string
+	query := `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_name = $1 AND table_schema = 'public' AND column_default LIKE 'nextval(%'
+		ORDER BY ordinal_position;
+	`
+
+	rows, err := db.Query(query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
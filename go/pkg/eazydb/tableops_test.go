@@ -0,0 +1,118 @@
+package eazydb
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableOperationQuery(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	tests := []struct {
+		name string
+		op   *TableOperation
+		want string
+	}{
+		{"drop", c.DropTable("users").IfExists().Cascade(), "DROP TABLE IF EXISTS users CASCADE;"},
+		{"truncate", c.TruncateTable("users").RestartIdentity().Cascade(), "TRUNCATE TABLE users RESTART IDENTITY CASCADE;"},
+		{"rename", c.RenameTable("users", "customers").IfExists(), "ALTER TABLE IF EXISTS users RENAME TO customers;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.op.Dry().Exec()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Query != tt.want {
+				t.Errorf("got  %s\nwant %s", metadata.Query, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloneTable(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(query string) fakeResult {
+		switch {
+		case strings.Contains(query, "information_schema.columns"):
+			return fakeResult{columns: []string{"column_name"}, rows: [][]driver.Value{{"id"}}}
+		case strings.Contains(query, "pg_constraint"):
+			return fakeResult{
+				columns: []string{"conname", "columns", "relname", "ref_columns", "confdeltype", "confupdtype"},
+				rows:    [][]driver.Value{{"users_team_id_fkey", "team_id", "teams", "id", "c", "a"}},
+			}
+		}
+		return defaultResult(query)
+	}
+
+	if _, err := c.CloneTable("users", "users_backup").WithData().Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, stmt := range fake.statements() {
+		if !strings.Contains(stmt, "information_schema") && !strings.Contains(stmt, "pg_constraint") {
+			got = append(got, stmt)
+		}
+	}
+	want := []string{
+		"BEGIN",
+		"CREATE TABLE users_backup (LIKE users INCLUDING ALL);",
+		"CREATE SEQUENCE users_backup_id_seq OWNED BY users_backup.id;",
+		"ALTER TABLE users_backup ALTER COLUMN id SET DEFAULT nextval('users_backup_id_seq');",
+		"ALTER TABLE users_backup ADD CONSTRAINT users_backup_team_id_fkey FOREIGN KEY (team_id) REFERENCES teams (id) ON DELETE CASCADE ON UPDATE NO ACTION;",
+		"INSERT INTO users_backup SELECT * FROM users;",
+		"SELECT setval('users_backup_id_seq', COALESCE(MAX(id), 0) + 1, false) FROM users_backup;",
+		"COMMIT",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestTableOperationUpdatesRegistries(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.keys.set("users", []string{"id"})
+	c.softDeletes.add("users")
+
+	if _, err := c.RenameTable("users", "customers").Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if c.keys.get("users") != nil || c.keys.get("customers") == nil || !c.softDeletes.has("customers") {
+		t.Errorf("rename did not move the key and soft delete registrations")
+	}
+
+	if _, err := c.DropTable("customers").Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if c.keys.get("customers") != nil || c.softDeletes.has("customers") {
+		t.Errorf("drop did not forget the table")
+	}
+}
+
+func TestTableOperationErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	tests := []struct {
+		name string
+		op   *TableOperation
+		err  string
+	}{
+		{"no name", c.DropTable(""), "a table name is required"},
+		{"no new name", c.RenameTable("users", ""), "a new table name is required"},
+		{"if exists on truncate", c.TruncateTable("users").IfExists(), "IfExists can only be used"},
+		{"cascade on rename", c.RenameTable("users", "customers").Cascade(), "Cascade can only be used"},
+		{"restart identity on drop", c.DropTable("users").RestartIdentity(), "RestartIdentity can only be used"},
+		{"with data on drop", c.DropTable("users").WithData(), "WithData can only be used"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.op.Dry().Exec()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package eazydb
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Timestamps can be embedded in a struct so Add sets created_at and updated_at, and Update sets updated_at
+//
+//	type User struct {
+//		eazydb.Timestamps
+//		ID   int    `json:"id"`
+//		Name string `json:"name"`
+//	}
+//
+// Other fields opt in with `db:",createdat"` or `db:",updatedat"`
+type Timestamps struct {
+	CreatedAt time.Time `json:"created_at" db:",createdat"`
+	UpdatedAt time.Time `json:"updated_at" db:",updatedat"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// now reads the clock set in ClientOptions, falling back to time.Now
+func (q *Query) now() time.Time {
+	if q.clock != nil {
+		return q.clock()
+	}
+	return time.Now()
+}
+
+// columnFields returns the fields of a struct type, with the fields of untagged embedded
+// structs such as Timestamps promoted as if they were declared on the struct
+func columnFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" && f.Type.Kind() == reflect.Struct && f.Type != timeType {
+			for _, inner := range columnFields(f.Type) {
+				inner.Index = append([]int{i}, inner.Index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// timestampFields returns the fields tagged createdat and updatedat, either can be nil
+func timestampFields(t reflect.Type) (*reflect.StructField, *reflect.StructField, error) {
+	var created, updated *reflect.StructField
+	for _, f := range columnFields(t) {
+		opts := parseTag(f)
+		if !opts.createdAt && !opts.updatedAt {
+			continue
+		}
+		if f.Type != timeType && f.Type != reflect.PointerTo(timeType) {
+			return nil, nil, fmt.Errorf("%v is tagged as a timestamp but is %v, expected time.Time", f.Name, f.Type)
+		}
+		f := f
+		if (opts.createdAt && created != nil) || (opts.updatedAt && updated != nil) {
+			return nil, nil, fmt.Errorf("%v has more than one created_at or updated_at field", t)
+		}
+		if opts.createdAt {
+			created = &f
+		}
+		if opts.updatedAt {
+			updated = &f
+		}
+	}
+	return created, updated, nil
+}
+
+func setTime(v reflect.Value, now time.Time) {
+	if v.Kind() == reflect.Ptr {
+		v.Set(reflect.ValueOf(&now))
+		return
+	}
+	v.Set(reflect.ValueOf(now))
+}
+
+// stampInsert sets the unset timestamps of the rows passed to Add. An upsert that can update
+// the row always sets updated_at. Rows passed by pointer or in a slice are updated in place
+// so the caller sees the values that were written
+func (q *Query) stampInsert() error {
+	elem := rowType(q.fields)
+	if elem == nil {
+		return nil
+	}
+	created, updated, err := timestampFields(elem)
+	if err != nil || (created == nil && updated == nil) {
+		return err
+	}
+
+	now := q.now()
+	upsert := q.conflict != nil && !q.conflict.nothing
+	q.fields, err = eachRow(q.fields, func(row reflect.Value) error {
+		if created != nil && row.FieldByIndex(created.Index).IsZero() {
+			setTime(row.FieldByIndex(created.Index), now)
+		}
+		if updated != nil && (upsert || row.FieldByIndex(updated.Index).IsZero()) {
+			setTime(row.FieldByIndex(updated.Index), now)
+		}
+		return nil
+	})
+	return err
+}
+
+// stampUpdate sets updated_at when the struct passed to Update has an updatedat field, unless
+// the column was picked with Columns or set explicitly
+func (q *Query) stampUpdate() error {
+	v := reflect.ValueOf(q.fields)
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	_, updated, err := timestampFields(v.Type())
+	if err != nil || updated == nil {
+		return err
+	}
+	name := updated.Tag.Get("json")
+	if isKey(q.columns, name) || hasField(q.sets, name) {
+		return nil
+	}
+
+	now := q.now()
+	if v.CanSet() {
+		setTime(v.FieldByIndex(updated.Index), now)
+	}
+	q.sets = append(q.sets, field{Name: name, Val: now})
+	return nil
+}
+
+// cloneRows copies the structs in rows, which can be shaped any way eachRow accepts, so they
+// can be changed without the caller seeing it
+func cloneRows(rows interface{}) interface{} {
+	v := reflect.ValueOf(rows)
+	if !v.IsValid() {
+		return rows
+	}
+	return cloneValue(v).Interface()
+}
+
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		if v.Kind() == reflect.Interface {
+			return cloneValue(v.Elem())
+		}
+		c := reflect.New(v.Elem().Type())
+		c.Elem().Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package eazydb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type stampedUser struct {
+	Timestamps
+	Name string `json:"name"`
+}
+
+func stampedClient(t *testing.T) (*Client, *fakeDB, time.Time) {
+	t.Helper()
+	c, fake := newTestClient(t)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.clock = func() time.Time { return now }
+	return c, fake, now
+}
+
+func TestStampInsert(t *testing.T) {
+	c, fake, now := stampedClient(t)
+	created := now.Add(-time.Hour)
+	users := []stampedUser{{Name: "Mat"}, {Name: "Ann", Timestamps: Timestamps{CreatedAt: created}}}
+
+	if _, err := c.Table("users").Add(users).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if !users[0].CreatedAt.Equal(now) || !users[0].UpdatedAt.Equal(now) {
+		t.Errorf("got %+v, want both timestamps set", users[0].Timestamps)
+	}
+	if !users[1].CreatedAt.Equal(created) {
+		t.Errorf("got created_at %v, a set created_at should be kept", users[1].CreatedAt)
+	}
+	if got := fake.queries(); len(got) != 1 || !strings.HasPrefix(got[0], "INSERT INTO users (created_at, updated_at, name) VALUES") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestStampUpdate(t *testing.T) {
+	c, fake, now := stampedClient(t)
+	user := &stampedUser{Name: "Mat"}
+
+	if _, err := c.Table("users").Update(user).Where(*Int("id").Equals(1)).Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if !user.UpdatedAt.Equal(now) || !user.CreatedAt.IsZero() {
+		t.Errorf("got %+v, want only updated_at set", user.Timestamps)
+	}
+	if got := fake.queries(); len(got) != 1 || !strings.Contains(got[0], "updated_at = ") || strings.Contains(got[0], "created_at") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDryRunLeavesRowsUntouched(t *testing.T) {
+	c, fake, _ := stampedClient(t)
+	users := []stampedUser{{Name: "Mat"}}
+	user := &stampedUser{Name: "Ann"}
+
+	added, err := c.Table("users").Add(users).Dry().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := c.Table("users").Update(user).Where(*Int("id").Equals(1)).Dry().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !users[0].CreatedAt.IsZero() || !user.UpdatedAt.IsZero() {
+		t.Errorf("a dry run set timestamps on the caller's rows: %+v and %+v", users[0].Timestamps, user.Timestamps)
+	}
+	if !strings.Contains(added.Query, "created_at") || !strings.Contains(updated.Query, "updated_at") {
+		t.Errorf("the dry run query should still show the timestamps, got %q and %q", added.Query, updated.Query)
+	}
+	if got := fake.statements(); len(got) != 0 {
+		t.Errorf("a dry run reached the database: %q", got)
+	}
+}
+
+func TestTimestampTagsMustBeTimes(t *testing.T) {
+	c, _, _ := stampedClient(t)
+	_, err := c.Table("users").Add(struct {
+		Created string `json:"created" db:",createdat"`
+	}{"now"}).Dry().Exec()
+	if err == nil || !strings.Contains(err.Error(), "expected time.Time") {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestTimeValues(t *testing.T) {
+	c, _, now := stampedClient(t)
+	local := now.In(time.FixedZone("AEST", 10*60*60)).Add(123456 * time.Microsecond)
+
+	metadata, err := c.Table("users").Add(stampedUser{Name: "Mat", Timestamps: Timestamps{CreatedAt: local}}).Dry().Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT INTO users (created_at, updated_at, name) VALUES ($1, $2, $3);"
+	if metadata.Query != want {
+		t.Errorf("got  %s\nwant %s", metadata.Query, want)
+	}
+	// times are sent in UTC, the zone TIMESTAMP columns are written in
+	for i, want := range []string{"2024-01-02 03:04:05.123456", "2024-01-02 03:04:05"} {
+		got, _ := metadata.Args[i].(time.Time)
+		if got.Location() != time.UTC || got.Format(timestampLayout) != want {
+			t.Errorf("got arg %v, want %s UTC", metadata.Args[i], want)
+		}
+	}
+
+	tables := createTable(t, func(c *Client) *TableInstance { return c.NewTable("users").Fields(stampedUser{}) })
+	if want := "CREATE TABLE IF NOT EXISTS users (created_at TIMESTAMP, updated_at TIMESTAMP, name TEXT);"; len(tables) != 1 || tables[0] != want {
+		t.Errorf("got  %q\nwant %q", tables, want)
+	}
+}
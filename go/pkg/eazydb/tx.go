@@ -0,0 +1,64 @@
+package eazydb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Tx is a transaction. Queries started from it with Table run inside it
+type Tx struct {
+	*sql.Tx
+	client  *Client
+	attempt int
+}
+
+// Attempt is 1 the first time the transaction function runs, and counts up if it is retried
+func (tx *Tx) Attempt() int {
+	return tx.attempt
+}
+
+// Table starts a query that runs inside the transaction
+func (tx *Tx) Table(name string) *Query {
+	q := tx.client.Table(name)
+	q.tx = tx.Tx
+	return q
+}
+
+// Transaction runs fn inside a transaction. It is committed if fn returns nil and rolled back
+// if fn returns an error or panics. With ClientOptions.Retry set, fn is run again in a new
+// transaction when it or the commit fails with a transient error, so fn should not have
+// side effects outside the database
+//
+//	err := c.Transaction(ctx, func(tx *eazydb.Tx) error {
+//		_, err := tx.Table("users").Add(&user).ExecContext(ctx)
+//		return err
+//	})
+func (c *Client) Transaction(ctx context.Context, fn func(tx *Tx) error) error {
+	_, err := retry(ctx, c.retry, c.log, "transaction", func(attempt int) error {
+		return c.transaction(ctx, attempt, fn)
+	})
+	return err
+}
+
+func (c *Client) transaction(ctx context.Context, attempt int, fn func(tx *Tx) error) error {
+	sqlTx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			sqlTx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&Tx{Tx: sqlTx, client: c, attempt: attempt}); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil {
+			c.log.Error("could not roll back transaction", "error", rbErr)
+		}
+		return err
+	}
+	return sqlTx.Commit()
+}
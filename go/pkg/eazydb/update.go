@@ -0,0 +1,140 @@
+package eazydb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// Columns limits an update or add to the given columns. They are set even when zero valued,
+// so Update(&User{Age: 0}).Columns("age") sets age to 0
+func (q *Query) Columns(columns ...string) *Query {
+	if q.op != dbtypes.UPDATE && q.op != dbtypes.INSERT {
+		q.err = fmt.Errorf("Columns can only be used with Update or Add, table operation is %v", q.op)
+		return q
+	}
+	if len(columns) == 0 {
+		q.err = fmt.Errorf("Columns requires at least one column")
+		return q
+	}
+	q.columns = columns
+	return q
+}
+
+// UpdateMap sets each column in the map to its value, including zero values
+//
+//	table.UpdateMap(map[string]any{"age": 0, "active": false})
+func (q *Query) UpdateMap(values map[string]interface{}) *Query {
+	if q.op != "" {
+		q.err = fmt.Errorf("table operation already set to %v and so cannot be set to update", q.op)
+	}
+	if len(values) == 0 {
+		q.err = fmt.Errorf("UpdateMap requires at least one column")
+	}
+	q.op = dbtypes.UPDATE
+	q.fields = values
+	return q
+}
+
+// constructUpdateFields returns the fields to set. Zero values are skipped unless
+// the columns were picked with Columns or passed as a map
+func (q *Query) constructUpdateFields() ([]field, error) {
+	if q.fields == nil {
+		if len(q.sets) == 0 {
+			return nil, fmt.Errorf("nothing to update, pass a struct to Update or use Set")
+		}
+		if len(q.columns) > 0 {
+			return nil, fmt.Errorf("Columns requires a struct to be passed to Update")
+		}
+		return q.sets, nil
+	}
+
+	fields, err := q.constructStructUpdateFields()
+	if err != nil {
+		return nil, err
+	}
+	return mergeSets(fields, q.sets), nil
+}
+
+func (q *Query) constructStructUpdateFields() ([]field, error) {
+	if len(q.columns) == 0 {
+		// reading every field first surfaces errors that have nothing to do with zero values
+		if _, err := constructFields(q.fields, false); err != nil {
+			return nil, err
+		}
+		fields, err := constructFields(q.fields, true)
+		if err == nil {
+			return fields, nil
+		}
+		// every field is zero valued, which is fine when Set supplies the columns
+		if len(q.sets) > 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("nothing to update, every field of %T is zero valued. Zero values are only set when picked with Columns", q.fields)
+	}
+
+	return pickColumns(q.fields, q.columns)
+}
+
+// pickColumns returns the fields for the given columns in that order, including zero values
+func pickColumns(rawFields interface{}, columns []string) ([]field, error) {
+	all, err := constructFields(rawFields, false)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]field, 0, len(columns))
+	for _, col := range columns {
+		found := false
+		for _, f := range all {
+			if f.Name == col {
+				fields = append(fields, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("column %v was passed to Columns but is not a field of %T", col, rawFields)
+		}
+	}
+	return fields, nil
+}
+
+// constructMapFields returns a field per key, sorted so the query is stable
+func constructMapFields(m reflect.Value) ([]field, error) {
+	if m.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("expected map with string keys, got %v", m.Type())
+	}
+
+	keys := make([]string, 0, m.Len())
+	for _, k := range m.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+
+	fields := make([]field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, field{
+			Name: k,
+			Val:  derefVal(m.MapIndex(reflect.ValueOf(k).Convert(m.Type().Key()))),
+		})
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no valid fields found, the map is empty")
+	}
+	return fields, nil
+}
+
+// derefVal returns the value a pointer points at, or nil for a nil pointer
+func derefVal(val reflect.Value) interface{} {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	return val.Interface()
+}
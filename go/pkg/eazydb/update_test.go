@@ -0,0 +1,103 @@
+package eazydb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type updateUser struct {
+	Name   string  `json:"name"`
+	Age    int     `json:"age"`
+	Active bool    `json:"active"`
+	Nick   *string `json:"nick"`
+}
+
+func TestUpdateQuery(t *testing.T) {
+	c, _ := newTestClient(t)
+	empty := ""
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  string
+		args  []interface{}
+	}{
+		{
+			name:  "zero values are skipped",
+			query: c.Table("users").Update(updateUser{Name: "Mat"}).Where(*String("name").Equals("Mat")),
+			want:  "UPDATE users SET name = $1 WHERE name = $2",
+			args:  []interface{}{"Mat", "Mat"},
+		},
+		{
+			name:  "columns set zero values",
+			query: c.Table("users").Update(&updateUser{Name: "Mat"}).Columns("age", "active").Where(*String("name").Equals("Mat")),
+			want:  "UPDATE users SET age = $1, active = $2 WHERE name = $3",
+			args:  []interface{}{0, false, "Mat"},
+		},
+		{
+			name:  "pointers are set when not nil",
+			query: c.Table("users").Update(updateUser{Nick: &empty}).Where(*Int("age").Equals(1)),
+			want:  "UPDATE users SET nick = $1 WHERE age = $2",
+			args:  []interface{}{"", 1},
+		},
+		{
+			name:  "maps set every key in order",
+			query: c.Table("users").UpdateMap(map[string]interface{}{"name": "", "age": 0, "active": false}).Where(*Int("age").Equals(1)),
+			want:  "UPDATE users SET active = $1, age = $2, name = $3 WHERE age = $4",
+			args:  []interface{}{false, 0, "", 1},
+		},
+		{
+			name:  "zero valued struct with set",
+			query: c.Table("users").Update(updateUser{}).Set("name", "Mat").Where(*Int("age").Equals(1)),
+			want:  "UPDATE users SET name = $1 WHERE age = $2",
+			args:  []interface{}{"Mat", 1},
+		},
+		{
+			name:  "columns limit an add",
+			query: c.Table("users").Add(updateUser{Name: "Mat"}).Columns("name", "age"),
+			want:  "INSERT INTO users (name, age) VALUES ($1, $2);",
+			args:  []interface{}{"Mat", 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.query.Dry().Exec()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Query != tt.want {
+				t.Errorf("got  %s\nwant %s", metadata.Query, tt.want)
+			}
+			if !reflect.DeepEqual(metadata.Args, tt.args) {
+				t.Errorf("got args %v, want %v", metadata.Args, tt.args)
+			}
+		})
+	}
+}
+
+func TestUpdateErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	tests := []struct {
+		name  string
+		query *Query
+		err   string
+	}{
+		{"columns on get", c.Table("users").Get(updateUser{}).Columns("age"), "Columns can only be used with Update or Add"},
+		{"no columns", c.Table("users").Update(updateUser{}).Columns(), "at least one column"},
+		{"unknown column", c.Table("users").Update(updateUser{}).Columns("email").Where(*Int("age").Equals(1)), "column email was passed to Columns"},
+		{"every field zero", c.Table("users").Update(updateUser{}).Where(*Int("age").Equals(1)), "every field of eazydb.updateUser is zero valued"},
+		{"empty map", c.Table("users").UpdateMap(nil), "UpdateMap requires at least one column"},
+		{"not a struct with set", c.Table("users").Update(123).Set("name", "Mat").Where(*Int("age").Equals(1)), "expected struct, got int"},
+		{"untagged struct with set", c.Table("users").Update(struct{ Name string }{"Mat"}).Set("age", 1).Where(*Int("age").Equals(1)), "no valid fields found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.query.Dry().Exec()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
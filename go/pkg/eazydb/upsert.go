@@ -0,0 +1,90 @@
+package eazydb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+type conflictClause struct {
+	columns []string
+	updates []string
+	nothing bool
+}
+
+// OnConflict sets the columns that identify a conflicting row when adding data.
+// Follow with DoUpdate or DoNothing.
+func (q *Query) OnConflict(columns ...string) *Query {
+	if q.op != dbtypes.INSERT {
+		q.err = fmt.Errorf("OnConflict can only be used with Add, table operation is %v", q.op)
+		return q
+	}
+	if q.conflict == nil {
+		q.conflict = &conflictClause{}
+	}
+	q.conflict.columns = columns
+	return q
+}
+
+// DoUpdate overwrites the given columns with the new values when a row conflicts
+func (q *Query) DoUpdate(columns ...string) *Query {
+	if q.conflict == nil {
+		q.err = fmt.Errorf("DoUpdate requires OnConflict to be set first")
+		return q
+	}
+	if len(columns) == 0 {
+		q.err = fmt.Errorf("DoUpdate requires at least one column")
+		return q
+	}
+	q.conflict.updates = columns
+	q.conflict.nothing = false
+	return q
+}
+
+// DoNothing skips rows that conflict with existing rows
+func (q *Query) DoNothing() *Query {
+	if q.op != dbtypes.INSERT {
+		q.err = fmt.Errorf("DoNothing can only be used with Add, table operation is %v", q.op)
+		return q
+	}
+	if q.conflict == nil {
+		q.conflict = &conflictClause{}
+	}
+	q.conflict.nothing = true
+	q.conflict.updates = nil
+	return q
+}
+
+// constructConflictClause renders the postgres conflict clause, for example
+//
+//	ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name
+func (q *Query) constructConflictClause() (string, error) {
+	if q.conflict == nil {
+		return "", nil
+	}
+	if !q.conflict.nothing && len(q.conflict.updates) == 0 {
+		return "", fmt.Errorf("OnConflict requires either DoUpdate or DoNothing")
+	}
+
+	stmt := " ON CONFLICT"
+	if len(q.conflict.columns) > 0 {
+		stmt += fmt.Sprintf(" (%s)", strings.Join(q.conflict.columns, ", "))
+	} else if !q.conflict.nothing {
+		return "", fmt.Errorf("DoUpdate requires the conflicting columns to be passed to OnConflict")
+	}
+	if q.conflict.nothing {
+		return stmt + " DO NOTHING", nil
+	}
+	sets := make([]string, len(q.conflict.updates))
+	for i, col := range q.conflict.updates {
+		sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", col, col)
+	}
+	stmt += " DO UPDATE SET " + strings.Join(sets, ", ")
+	return stmt, nil
+}
+
+// countsUpserts reports whether the query returns a row per insert or update
+func (q *Query) countsUpserts() bool {
+	return q.conflict != nil && !q.conflict.nothing
+}
@@ -0,0 +1,104 @@
+package eazydb
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type upsertUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Age   int    `json:"age"`
+}
+
+func TestUpsertQuery(t *testing.T) {
+	c, _ := newTestClient(t)
+	users := []upsertUser{{"Mat", "mat@example.com", 24}, {"Ann", "ann@example.com", 31}}
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  string
+		args  []interface{}
+	}{
+		{
+			name:  "do update",
+			query: c.Table("users").Add(users).OnConflict("email").DoUpdate("name", "age"),
+			want: "INSERT INTO users (name, email, age) VALUES ($1, $2, $3), ($4, $5, $6)" +
+				" ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name, age = EXCLUDED.age RETURNING (xmax = 0) AS eazydb_inserted;",
+			args: []interface{}{"Mat", "mat@example.com", 24, "Ann", "ann@example.com", 31},
+		},
+		{
+			name:  "do nothing",
+			query: c.Table("users").Add(users[0]).OnConflict("email").DoNothing(),
+			want:  "INSERT INTO users (name, email, age) VALUES ($1, $2, $3) ON CONFLICT (email) DO NOTHING;",
+			args:  []interface{}{"Mat", "mat@example.com", 24},
+		},
+		{
+			name:  "do nothing on any conflict",
+			query: c.Table("users").Add(users[0]).DoNothing(),
+			want:  "INSERT INTO users (name, email, age) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;",
+			args:  []interface{}{"Mat", "mat@example.com", 24},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := tt.query.Dry().Exec()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Query != tt.want {
+				t.Errorf("got  %s\nwant %s", metadata.Query, tt.want)
+			}
+			if !reflect.DeepEqual(metadata.Args, tt.args) {
+				t.Errorf("got args %v, want %v", metadata.Args, tt.args)
+			}
+		})
+	}
+}
+
+func TestUpsertErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+	user := upsertUser{"Mat", "mat@example.com", 24}
+
+	tests := []struct {
+		name  string
+		query *Query
+		err   string
+	}{
+		{"update without conflict", c.Table("users").Add(user).DoUpdate("name"), "DoUpdate requires OnConflict"},
+		{"conflict without action", c.Table("users").Add(user).OnConflict("email"), "either DoUpdate or DoNothing"},
+		{"update without columns", c.Table("users").Add(user).DoNothing().DoUpdate("name"), "DoUpdate requires the conflicting columns"},
+		{"conflict on get", c.Table("users").Get(upsertUser{}).OnConflict("email"), "OnConflict can only be used with Add"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.query.Dry().Exec()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUpsertCountsInsertsAndUpdates(t *testing.T) {
+	c, fake := newTestClient(t)
+	fake.respond = func(string) fakeResult {
+		return fakeResult{
+			columns: []string{"eazydb_inserted"},
+			rows:    [][]driver.Value{{true}, {false}, {true}},
+		}
+	}
+
+	users := []upsertUser{{Name: "a", Email: "a@x.io"}, {Name: "b", Email: "b@x.io"}, {Name: "c", Email: "c@x.io"}}
+	metadata, err := c.Table("users").Add(users).OnConflict("email").DoUpdate("name").Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.RowsInserted != 2 || metadata.RowsUpdated != 1 || metadata.RowsAffected != 3 {
+		t.Errorf("got %v inserted, %v updated and %v affected, want 2, 1 and 3",
+			metadata.RowsInserted, metadata.RowsUpdated, metadata.RowsAffected)
+	}
+}
@@ -0,0 +1,236 @@
+package eazydb
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mperkins808/eazydb/go/pkg/eazydb/dbtypes"
+)
+
+// Validator can be implemented by models to check rows before Add or Update builds the query.
+// Fields can also be checked with a `validate` tag, eg: `validate:"required,max=120,email"`
+//
+// Supported rules are
+//
+//	required    the field is not zero valued, or not nil for pointers
+//	min=n       strings and slices have at least n characters or items, numbers are at least n
+//	max=n       strings and slices have at most n characters or items, numbers are at most n.
+//	            On strings it also makes NewTable create a VARCHAR(n) column
+//	email       the field is a plain email address
+//	oneof=a b   the field is one of the space separated values
+type Validator interface {
+	Validate() error
+}
+
+// FieldError is a rule a field failed. Field is empty for errors returned by Validate
+type FieldError struct {
+	// position of the row in the slice passed to Add, 0 for a single row
+	Row     int
+	Field   string
+	Rule    string
+	Message string
+}
+
+// ValidationError lists every field that failed validation
+type ValidationError struct {
+	Table  string
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Message
+	}
+	return fmt.Sprintf("validation failed on %s: %s", e.Table, strings.Join(msgs, "; "))
+}
+
+type rule struct {
+	name string
+	arg  string
+}
+
+func parseRules(tag string) []rule {
+	var rules []rule
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		rules = append(rules, rule{name: name, arg: arg})
+	}
+	return rules
+}
+
+// maxLength returns the max= rule of a field, 0 if it has none
+func maxLength(f reflect.StructField) int {
+	for _, r := range parseRules(f.Tag.Get("validate")) {
+		if r.name == "max" {
+			n, _ := strconv.Atoi(r.arg)
+			return n
+		}
+	}
+	return 0
+}
+
+// columnType narrows TEXT to VARCHAR(n) when the field has a max length
+func columnType(f reflect.StructField, parsed dbtypes.ValType) dbtypes.ValType {
+	if n := maxLength(f); parsed == dbtypes.TEXT && n > 0 {
+		return dbtypes.ValType(fmt.Sprintf("VARCHAR(%d)", n))
+	}
+	return parsed
+}
+
+// validate checks the rows passed to Add or Update. Updates only check the columns they set
+func (q *Query) validate() error {
+	if q.op != dbtypes.INSERT && q.op != dbtypes.UPDATE {
+		return nil
+	}
+	t := rowType(q.fields)
+	if t == nil {
+		return nil
+	}
+
+	var only map[string]bool
+	if q.op == dbtypes.UPDATE {
+		fields, err := q.constructStructUpdateFields()
+		if err != nil {
+			return err
+		}
+		only = make(map[string]bool)
+		for _, f := range fields {
+			only[f.Name] = true
+		}
+	}
+
+	verr := &ValidationError{Table: q.name}
+	i := 0
+	_, err := eachRow(q.fields, func(row reflect.Value) error {
+		errs, err := validateRow(row, only)
+		if err != nil {
+			return err
+		}
+		for _, fe := range errs {
+			fe.Row = i
+			verr.Fields = append(verr.Fields, fe)
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// validateRow checks the tagged fields of a row, then calls its Validate method
+func validateRow(row reflect.Value, only map[string]bool) ([]FieldError, error) {
+	var errs []FieldError
+	for _, f := range columnFields(row.Type()) {
+		name := f.Tag.Get("json")
+		tag := f.Tag.Get("validate")
+		if name == "" || tag == "" || (only != nil && !only[name]) {
+			continue
+		}
+		for _, r := range parseRules(tag) {
+			msg, err := checkRule(row.FieldByIndex(f.Index), r)
+			if err != nil {
+				return nil, fmt.Errorf("%v has an invalid validate tag: %v", f.Name, err)
+			}
+			if msg != "" {
+				errs = append(errs, FieldError{Field: name, Rule: r.name, Message: name + " " + msg})
+			}
+		}
+	}
+
+	if v, ok := row.Addr().Interface().(Validator); ok {
+		if err := v.Validate(); err != nil {
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				return append(errs, verr.Fields...), nil
+			}
+			errs = append(errs, FieldError{Rule: "Validate", Message: err.Error()})
+		}
+	}
+	return errs, nil
+}
+
+// checkRule returns why the value breaks the rule, or an empty string if it doesn't
+func checkRule(v reflect.Value, r rule) (string, error) {
+	if r.name == "required" {
+		if v.IsZero() {
+			return "is required", nil
+		}
+		return "", nil
+	}
+	// other rules only apply to values that are set
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	switch r.name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(r.arg, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s needs a number, got %q", r.name, r.arg)
+		}
+		size, unit, err := measure(v)
+		if err != nil {
+			return "", err
+		}
+		if r.name == "min" && size < limit {
+			return fmt.Sprintf("must be at least %v%s", r.arg, unit), nil
+		}
+		if r.name == "max" && size > limit {
+			return fmt.Sprintf("must be at most %v%s", r.arg, unit), nil
+		}
+	case "email":
+		if v.Kind() != reflect.String {
+			return "", fmt.Errorf("email can only be used on strings")
+		}
+		addr, err := mail.ParseAddress(v.String())
+		if err != nil || addr.Address != v.String() {
+			return "is not a valid email address", nil
+		}
+	case "oneof":
+		val := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(r.arg) {
+			if val == option {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(r.arg), ", ")), nil
+	default:
+		return "", fmt.Errorf("unknown rule %s", r.name)
+	}
+	return "", nil
+}
+
+// measure returns the length of strings and slices, or the value of numbers
+func measure(v reflect.Value) (float64, string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", nil
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), " items", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "", nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", nil
+	}
+	return 0, "", fmt.Errorf("min and max cannot be used on %v", v.Type())
+}
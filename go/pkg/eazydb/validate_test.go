@@ -0,0 +1,83 @@
+package eazydb
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type validatedUser struct {
+	Name  string `json:"name" validate:"required,max=5"`
+	Email string `json:"email" validate:"required,email"`
+	Age   int    `json:"age" validate:"min=18"`
+	Role  string `json:"role" validate:"oneof=admin member"`
+}
+
+func (u *validatedUser) Validate() error {
+	if u.Role == "admin" && u.Age < 21 {
+		return errors.New("admins must be at least 21")
+	}
+	return nil
+}
+
+func TestValidateRows(t *testing.T) {
+	c, fake := newTestClient(t)
+	users := []validatedUser{
+		{Name: "Mat", Email: "mat@x.io", Age: 30, Role: "member"},
+		{Name: "Matthew", Email: "Mat <mat@x.io>", Age: 17, Role: "owner"},
+		{Email: "ann@x.io", Age: 19, Role: "admin"},
+	}
+
+	_, err := c.Table("users").Add(users).Exec()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got error %v, want a ValidationError", err)
+	}
+	want := []FieldError{
+		{Row: 1, Field: "name", Rule: "max", Message: "name must be at most 5 characters"},
+		{Row: 1, Field: "email", Rule: "email", Message: "email is not a valid email address"},
+		{Row: 1, Field: "age", Rule: "min", Message: "age must be at least 18"},
+		{Row: 1, Field: "role", Rule: "oneof", Message: "role must be one of admin, member"},
+		{Row: 2, Field: "name", Rule: "required", Message: "name is required"},
+		{Row: 2, Rule: "Validate", Message: "admins must be at least 21"},
+	}
+	if !reflect.DeepEqual(verr.Fields, want) {
+		t.Errorf("got  %+v\nwant %+v", verr.Fields, want)
+	}
+	if got := fake.statements(); len(got) != 0 {
+		t.Errorf("invalid rows reached the database: %q", got)
+	}
+}
+
+func TestValidateUpdateOnlyChecksSetColumns(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	// name and email are empty but not being set
+	_, err := c.Table("users").Update(validatedUser{Age: 40}).Where(*Int("id").Equals(1)).Dry().Exec()
+	if err != nil {
+		t.Fatalf("got error %v", err)
+	}
+	_, err = c.Table("users").Update(validatedUser{Age: 4}).Where(*Int("id").Equals(1)).Dry().Exec()
+	if err == nil || !strings.Contains(err.Error(), "age must be at least 18") {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestValidateTagErrors(t *testing.T) {
+	c, _ := newTestClient(t)
+	_, err := c.Table("users").Add(struct {
+		Age int `json:"age" validate:"email"`
+	}{1}).Dry().Exec()
+	if err == nil || !strings.Contains(err.Error(), "invalid validate tag") {
+		t.Fatalf("got error %v", err)
+	}
+}
+
+func TestMaxMakesVarchar(t *testing.T) {
+	got := createTable(t, func(c *Client) *TableInstance { return c.NewTable("users").Fields(validatedUser{}) })
+	want := "CREATE TABLE IF NOT EXISTS users (name VARCHAR(5), email TEXT, age INT, role TEXT);"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
@@ -2,10 +2,15 @@ package eazydb
 
 import (
 	"fmt"
+	"strings"
 )
 
+// Condition is part of a WHERE clause. The values it compares with are kept apart from
+// its SQL and bound as $n args once the statement they end up in is built, so text
+// holds the SQL either side of each value
 type Condition struct {
-	clause string
+	text []string
+	args []arg
 }
 
 func (q *Query) Where(conditions ...Condition) *Query {
@@ -14,13 +19,67 @@ func (q *Query) Where(conditions ...Condition) *Query {
 }
 
 func (c *Condition) Or(condition Condition) *Condition {
-	if c.clause == "" {
+	if len(c.text) == 0 {
 		return c
 	}
-	c.clause = fmt.Sprintf("(%s OR %s)", c.clause, condition.clause)
+	or := &Condition{}
+	or.writeSQL("(")
+	or.writeCondition(*c)
+	or.writeSQL(" OR ")
+	or.writeCondition(condition)
+	or.writeSQL(")")
+	*c = *or
 	return c
 }
 
+// sqlCondition is a condition without values, eg: deleted_at IS NULL
+func sqlCondition(sql string) Condition {
+	return Condition{text: []string{sql}}
+}
+
+// compare produces a condition like name = $1
+func compare(name string, op string, val interface{}) *Condition {
+	c := &Condition{}
+	c.writeSQL(fmt.Sprintf("%s %s ", name, op))
+	c.writeValue(name, val)
+	return c
+}
+
+func (c *Condition) writeSQL(sql string) {
+	if len(c.text) == 0 {
+		c.text = []string{""}
+	}
+	c.text[len(c.text)-1] += sql
+}
+
+// writeValue adds a value compared with column
+func (c *Condition) writeValue(column string, val interface{}) {
+	c.writeSQL("")
+	c.args = append(c.args, arg{column: column, val: val})
+	c.text = append(c.text, "")
+}
+
+func (c *Condition) writeCondition(other Condition) {
+	for i, text := range other.text {
+		c.writeSQL(text)
+		if i < len(other.args) {
+			c.writeValue(other.args[i].column, other.args[i].val)
+		}
+	}
+}
+
+// render writes the condition into a statement, binding its values
+func (c Condition) render(b *binder) string {
+	var stmt strings.Builder
+	for i, text := range c.text {
+		stmt.WriteString(text)
+		if i < len(c.args) {
+			stmt.WriteString(b.bind(c.args[i].column, c.args[i].val))
+		}
+	}
+	return stmt.String()
+}
+
 type StrCond struct {
 	name string
 }
@@ -32,36 +91,26 @@ func String(name string) *StrCond {
 }
 
 func (s *StrCond) Equals(val string) *Condition {
-	return &Condition{
-		clause: fmt.Sprintf("%s = '%v'", s.name, val),
-	}
+	return compare(s.name, "=", val)
 }
 
 func (s *StrCond) NotEqual(val string) *Condition {
-	return &Condition{
-		clause: fmt.Sprintf("%s != '%v'", s.name, val),
-	}
+	return compare(s.name, "!=", val)
 }
 
 func (s *StrCond) Contains(val string) *Condition {
 	search := "%" + val + "%"
-	return &Condition{
-		clause: fmt.Sprintf("%s LIKE '%v'", s.name, search),
-	}
+	return compare(s.name, "LIKE", search)
 }
 
 func (s *StrCond) StartsWith(val string) *Condition {
 	search := val + "%"
-	return &Condition{
-		clause: fmt.Sprintf("%s LIKE '%v'", s.name, search),
-	}
+	return compare(s.name, "LIKE", search)
 }
 
 func (s *StrCond) EndsWith(val string) *Condition {
 	search := "%" + val
-	return &Condition{
-		clause: fmt.Sprintf("%s LIKE '%v'", s.name, search),
-	}
+	return compare(s.name, "LIKE", search)
 }
 
 type IntCond struct {
@@ -75,37 +124,25 @@ func Int(name string) *IntCond {
 }
 
 func (i *IntCond) Equals(val int) *Condition {
-	return &Condition{
-		clause: fmt.Sprintf("%s = %v", i.name, val),
-	}
+	return compare(i.name, "=", val)
 }
 
 func (i *IntCond) GreaterThan(val int) *Condition {
-	return &Condition{
-		clause: fmt.Sprintf("%s > %v", i.name, val),
-	}
+	return compare(i.name, ">", val)
 }
 
 func (i *IntCond) GreaterThanOrEqual(val int) *Condition {
-	return &Condition{
-		clause: fmt.Sprintf("%s >= %v", i.name, val),
-	}
+	return compare(i.name, ">=", val)
 }
 
 func (i *IntCond) LessThan(val int) *Condition {
-	return &Condition{
-		clause: fmt.Sprintf("%s < %v", i.name, val),
-	}
+	return compare(i.name, "<", val)
 }
 
 func (i *IntCond) LessThanOrEqual(val int) *Condition {
-	return &Condition{
-		clause: fmt.Sprintf("%s < %v", i.name, val),
-	}
+	return compare(i.name, "<", val)
 }
 
 func (i *IntCond) NotEqual(val int) *Condition {
-	return &Condition{
-		clause: fmt.Sprintf("%s != %v", i.name, val),
-	}
+	return compare(i.name, "!=", val)
 }